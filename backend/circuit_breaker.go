@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CircuitBreakerConfig configures ejection of failing backend addresses from client balancing
+type CircuitBreakerConfig struct {
+	// consecutive failures of address which open circuit
+	FailureThreshold int
+	// time while address is ejected, after that it receives requests again and is ejected after the first failure
+	OpenTimeout time.Duration
+	// status codes counted as failures, Unavailable and DeadlineExceeded by default
+	Codes []codes.Code
+}
+
+type addressState struct {
+	failures int
+	open     bool
+	halfOpen bool
+}
+
+type circuitBreaker struct {
+	cfg      CircuitBreakerConfig
+	onChange func()
+
+	lock      sync.Mutex
+	addresses map[string]*addressState
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig, onChange func()) *circuitBreaker {
+	if len(cfg.Codes) == 0 {
+		cfg.Codes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded}
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	return &circuitBreaker{
+		cfg:       cfg,
+		onChange:  onChange,
+		addresses: make(map[string]*addressState),
+	}
+}
+
+// report records result of call handled by address, addr is nil if call was not sent.
+// Connections are dialed with breakerDialer, so addr is resolver address as in isEjected.
+// Canceled call, e.g. by caller or by another hedged attempt, is neither success nor failure of address
+func (cb *circuitBreaker) report(addr net.Addr, err error) {
+	if cb == nil || addr == nil {
+		return
+	}
+	if status.Code(err) == codes.Canceled && !cb.isFailure(err) {
+		return
+	}
+	key := addr.String()
+
+	cb.lock.Lock()
+	state, ok := cb.addresses[key]
+	if !ok {
+		state = &addressState{}
+		cb.addresses[key] = state
+	}
+	if !cb.isFailure(err) {
+		state.failures = 0
+		state.halfOpen = false
+		cb.lock.Unlock()
+		return
+	}
+	state.failures++
+	opened := !state.open && (state.halfOpen || state.failures >= cb.cfg.FailureThreshold)
+	if opened {
+		state.open = true
+		state.halfOpen = false
+		time.AfterFunc(cb.cfg.OpenTimeout, func() {
+			cb.halfOpen(key)
+		})
+	}
+	cb.lock.Unlock()
+
+	if opened {
+		cb.onChange()
+	}
+}
+
+func (cb *circuitBreaker) halfOpen(key string) {
+	cb.lock.Lock()
+	state := cb.addresses[key]
+	state.open = false
+	state.halfOpen = true
+	state.failures = 0
+	cb.lock.Unlock()
+
+	cb.onChange()
+}
+
+func (cb *circuitBreaker) isEjected(address string) bool {
+	if cb == nil {
+		return false
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	state, ok := cb.addresses[address]
+	return ok && state.open
+}
+
+func (cb *circuitBreaker) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	for _, c := range cb.cfg.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// breakerDialer wraps dial to report dialed resolver address as remote address of connection,
+// so peer of call matches structure.AddressConfiguration even if it contains hostname
+func breakerDialer(dial func(ctx context.Context, address string) (net.Conn, error)) func(ctx context.Context, address string) (net.Conn, error) {
+	if dial == nil {
+		dial = func(ctx context.Context, address string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, "tcp", address)
+		}
+	}
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		conn, err := dial(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		return &resolverAddrConn{Conn: conn, addr: resolverAddr(address)}, nil
+	}
+}
+
+type resolverAddr string
+
+func (a resolverAddr) Network() string {
+	return "tcp"
+}
+
+func (a resolverAddr) String() string {
+	return string(a)
+}
+
+type resolverAddrConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c *resolverAddrConn) RemoteAddr() net.Addr {
+	return c.addr
+}
@@ -0,0 +1,230 @@
+// Package clientgen generates typed client package for module endpoints. Descriptors are known only at runtime,
+// so generator is called from small program in module repository, e.g. cmd/clientgen/main.go:
+//
+//	func main() {
+//		src, err := clientgen.Generate("objectclient", routes.EndpointDescriptors())
+//		if err != nil {
+//			panic(err)
+//		}
+//		_ = os.WriteFile("pkg/objectclient/client.go", src, 0644)
+//	}
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/integration-system/isp-lib/v2/backend"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/pkg/errors"
+)
+
+const backendPkgPath = "github.com/integration-system/isp-lib/v2/backend"
+
+var (
+	versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+type method struct {
+	name string
+	path string
+	req  string
+	resp string
+}
+
+type generator struct {
+	// package path -> alias
+	imports map[string]string
+	aliases map[string]bool
+}
+
+// Generate returns formatted source of package with Client struct which has typed backend.Method field per endpoint,
+// stream endpoints are skipped, invalid handlers are reported with error
+func Generate(packageName string, descriptors []structure.EndpointDescriptor) ([]byte, error) {
+	g := &generator{
+		imports: map[string]string{backendPkgPath: "backend"},
+		aliases: map[string]bool{"backend": true},
+	}
+
+	methods := make([]method, 0, len(descriptors))
+	names := make(map[string]bool)
+	for _, descriptor := range descriptors {
+		req, resp, err := backend.HandlerTypes(descriptor.Handler)
+		if errors.Is(err, backend.ErrStreamHandler) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithMessagef(err, "handler of %s", descriptor.Path)
+		}
+		m := method{path: descriptor.Path, name: methodName(descriptor.Path, true)}
+		if names[m.name] {
+			m.name = methodName(descriptor.Path, false)
+		}
+		if names[m.name] {
+			return nil, errors.Errorf("duplicate method name %s for path %s", m.name, descriptor.Path)
+		}
+		names[m.name] = true
+
+		if m.req, err = g.typeExpr(req); err != nil {
+			return nil, errors.WithMessagef(err, "request of %s", descriptor.Path)
+		}
+		if m.resp, err = g.typeExpr(resp); err != nil {
+			return nil, errors.WithMessagef(err, "response of %s", descriptor.Path)
+		}
+		methods = append(methods, m)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].name < methods[j].name
+	})
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("// Code generated by isp-lib clientgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", packageName)
+
+	pkgPaths := make([]string, 0, len(g.imports))
+	for pkgPath := range g.imports {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+	buf.WriteString("import (\n")
+	for _, pkgPath := range pkgPaths {
+		fmt.Fprintf(buf, "%s %q\n", g.imports[pkgPath], pkgPath)
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString("type Client struct {\n")
+	for _, m := range methods {
+		fmt.Fprintf(buf, "// %s\n%s backend.Method[%s, %s]\n", m.path, m.name, m.req, m.resp)
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func New(client backend.GrpcClient, opts ...backend.InvokeOption) *Client {\nreturn &Client{\n")
+	for _, m := range methods {
+		fmt.Fprintf(buf, "%s: backend.NewMethod[%s, %s](client, %q, opts...),\n", m.name, m.req, m.resp, m.path)
+	}
+	buf.WriteString("}\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.WithMessage(err, "format generated source")
+	}
+	return src, nil
+}
+
+// typeExpr returns go expression of type, nil type is represented as empty struct
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t == nil {
+		return "struct{}", nil
+	}
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if strings.ContainsAny(t.Name(), "[]") {
+			return "", errors.Errorf("generic type %s is not supported", t)
+		}
+		if strings.HasSuffix(t.PkgPath(), "/internal") || strings.Contains(t.PkgPath(), "/internal/") ||
+			t.PkgPath() == "main" {
+			return "", errors.Errorf("type %s is not importable", t)
+		}
+		if !token.IsExported(t.Name()) {
+			return "", errors.Errorf("unexported type %s", t)
+		}
+		return g.alias(t.PkgPath()) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return fmt.Sprintf("[%d]%s", t.Len(), elem), err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return fmt.Sprintf("map[%s]%s", key, elem), err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}", nil
+		}
+	}
+	return "", errors.Errorf("unnamed type %s is not supported", t)
+}
+
+func (g *generator) alias(pkgPath string) string {
+	if alias, ok := g.imports[pkgPath]; ok {
+		return alias
+	}
+	base := path.Base(pkgPath)
+	if versionSuffix.MatchString(base) && path.Dir(pkgPath) != "." {
+		base = path.Base(path.Dir(pkgPath))
+	}
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, base)
+	if base == "" || unicode.IsDigit(rune(base[0])) {
+		base = "pkg" + base
+	}
+
+	alias := base
+	for i := 2; g.aliases[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	g.aliases[alias] = true
+	g.imports[pkgPath] = alias
+	return alias
+}
+
+// methodName converts path "module/group/get_object" to "GroupGetObject", module and default group are omitted in short name
+func methodName(methodPath string, short bool) string {
+	parts := strings.Split(methodPath, "/")
+	if short && len(parts) > 1 {
+		parts = parts[1:]
+		if len(parts) > 1 && parts[0] == utils.MethodDefaultGroup {
+			parts = parts[1:]
+		}
+	}
+
+	name := new(strings.Builder)
+	for _, part := range parts {
+		upper := true
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				upper = true
+				continue
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			name.WriteRune(r)
+		}
+	}
+	if name.Len() == 0 || unicode.IsDigit(rune(name.String()[0])) {
+		return "Method" + name.String()
+	}
+	return name.String()
+}
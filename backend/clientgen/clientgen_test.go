@@ -0,0 +1,87 @@
+package clientgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/integration-system/isp-lib/v2/backend"
+	"github.com/integration-system/isp-lib/v2/config/schema"
+	"github.com/integration-system/isp-lib/v2/streaming"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+const expected = `// Code generated by isp-lib clientgen. DO NOT EDIT.
+
+package objectclient
+
+import (
+	backend "github.com/integration-system/isp-lib/v2/backend"
+	schema "github.com/integration-system/isp-lib/v2/config/schema"
+	structure "github.com/integration-system/isp-lib/v2/structure"
+)
+
+type Client struct {
+	// object/api/get
+	Get backend.Method[structure.AddressConfiguration, *structure.ModuleInfo]
+	// object/internal/get
+	InternalGet backend.Method[map[string]interface{}, schema.Schema]
+	// object/api/list
+	List backend.Method[struct{}, []structure.AddressConfiguration]
+}
+
+func New(client backend.GrpcClient, opts ...backend.InvokeOption) *Client {
+	return &Client{
+		Get:         backend.NewMethod[structure.AddressConfiguration, *structure.ModuleInfo](client, "object/api/get", opts...),
+		InternalGet: backend.NewMethod[map[string]interface{}, schema.Schema](client, "object/internal/get", opts...),
+		List:        backend.NewMethod[struct{}, []structure.AddressConfiguration](client, "object/api/list", opts...),
+	}
+}
+`
+
+func TestGenerate(t *testing.T) {
+	assert := assert.New(t)
+
+	descriptors := []structure.EndpointDescriptor{
+		backend.Handle("object/api/get", func(ctx context.Context, req structure.AddressConfiguration) (*structure.ModuleInfo, error) {
+			return nil, nil
+		}),
+		{
+			Path: "object/api/list",
+			Handler: func() ([]structure.AddressConfiguration, error) {
+				return nil, nil
+			},
+		},
+		{
+			Path: "object/internal/get",
+			Handler: func(md metadata.MD, req map[string]interface{}) (schema.Schema, error) {
+				return nil, nil
+			},
+		},
+		{
+			Path: "object/api/upload",
+			Handler: func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+				return nil
+			},
+		},
+	}
+	src, err := Generate("objectclient", descriptors)
+	assert.NoError(err)
+	assert.Equal(expected, string(src))
+
+	_, err = Generate("objectclient", []structure.EndpointDescriptor{
+		backend.Handle("object/api/anonymous", func(ctx context.Context, req struct{ A int }) (int, error) {
+			return 0, nil
+		}),
+	})
+	assert.Error(err)
+
+	// invalid handler is not skipped silently
+	_, err = Generate("objectclient", []structure.EndpointDescriptor{
+		{Path: "object/api/invalid", Handler: "not a function"},
+	})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "object/api/invalid")
+	}
+}
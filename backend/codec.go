@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// values of utils.BodyContentTypeHeader and utils.BodyAcceptHeader, json is used if header is not set
+const (
+	ContentTypeJson     = "application/json"
+	ContentTypeProtobuf = "application/protobuf"
+)
+
+// response of backend with grpc header which describes encoding of body
+type response struct {
+	msg    *isp.Message
+	header metadata.MD
+}
+
+func (r *response) contentType() string {
+	return metadataGetter(r.header)(utils.BodyContentTypeHeader)
+}
+
+// protoTarget returns message to unmarshal binary body to, ptr is pointer to proto message
+// or pointer to nil pointer of proto message which is allocated then
+func protoTarget(ptr interface{}) (proto.Message, bool) {
+	if msg, ok := ptr.(proto.Message); ok {
+		return msg, true
+	}
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Ptr {
+		return nil, false
+	}
+	elem := v.Elem()
+	if !elem.Type().Implements(protoMessageType) {
+		return nil, false
+	}
+	if elem.IsNil() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	return elem.Interface().(proto.Message), true
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// isProtoTarget is like protoTarget but doesn't allocate message
+func isProtoTarget(ptr interface{}) bool {
+	if _, ok := ptr.(proto.Message); ok {
+		return true
+	}
+	t := reflect.TypeOf(ptr)
+	return t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Ptr && t.Elem().Implements(protoMessageType)
+}
+
+// decodeBody unmarshals binary protobuf body if contentType is protobuf, json or struct body otherwise
+func decodeBody(msg *isp.Message, ptr interface{}, contentType string) error {
+	if contentType != ContentTypeProtobuf {
+		return readBody(msg, ptr)
+	}
+	target, ok := protoTarget(ptr)
+	if !ok {
+		return errors.Errorf("protobuf body is not supported for %T", ptr)
+	}
+	return proto.Unmarshal(msg.GetBytesBody(), target)
+}
+
+// encodeBody marshals data to binary protobuf if it is proto message and protobuf is accepted, to json otherwise,
+// returns used content type
+func encodeBody(data interface{}, acceptProtobuf bool) (*isp.Message, string, error) {
+	if pm, ok := data.(proto.Message); ok && acceptProtobuf && !reflect.ValueOf(pm).IsNil() {
+		bytes, err := proto.Marshal(pm)
+		if err != nil {
+			return nil, "", err
+		}
+		return &isp.Message{Body: &isp.Message_BytesBody{BytesBody: bytes}}, ContentTypeProtobuf, nil
+	}
+	msg, err := toBytes(data)
+	return msg, ContentTypeJson, err
+}
+
+// encodeResponse uses protobuf if client accepts it and result is non-nil proto message,
+// json is used if grpc header can't be set
+func (df *DefaultService) encodeResponse(ctx context.Context, result interface{}, md metadata.MD) (*isp.Message, error) {
+	acceptProtobuf := false
+	for _, accept := range md.Get(utils.BodyAcceptHeader) {
+		acceptProtobuf = acceptProtobuf || accept == ContentTypeProtobuf
+	}
+	msg, contentType, err := encodeBody(result, acceptProtobuf)
+	if err != nil || contentType != ContentTypeProtobuf {
+		return msg, err
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(utils.BodyContentTypeHeader, ContentTypeProtobuf)); err != nil {
+		return toBytes(result)
+	}
+	return msg, nil
+}
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func protobufService(contentTypes chan<- string) *DefaultService {
+	return NewDefaultService([]structure.EndpointDescriptor{
+		Handle("upper", func(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			contentTypes <- metadataGetter(md)(utils.BodyContentTypeHeader)
+			return wrapperspb.String(req.GetValue() + "!"), nil
+		}),
+	})
+}
+
+func TestProtobufBody(t *testing.T) {
+	contentTypes := make(chan string, 1)
+	service := protobufService(contentTypes)
+	addr, srv := startTestServer(service)
+	defer srv.Stop()
+	rxClient := newTestClient([]structure.AddressConfiguration{addr})
+	defer rxClient.Close()
+
+	clients := map[string]GrpcClient{"rx": rxClient, "loopback": NewLoopbackClient(service)}
+	for name, cli := range clients {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			upper := NewMethod[*wrapperspb.StringValue, *wrapperspb.StringValue](cli, "upper")
+			resp, err := upper.Invoke(context.Background(), 1, wrapperspb.String("a"), WithProtobufEncoding())
+			assert.NoError(err)
+			assert.Equal("a!", resp.GetValue())
+			assert.Equal(ContentTypeProtobuf, <-contentTypes)
+
+			// request is sent as json by default, response is still negotiated
+			resp = new(wrapperspb.StringValue)
+			assert.NoError(cli.Invoke("upper", 1, wrapperspb.String("b"), resp))
+			assert.Equal("b!", resp.GetValue())
+			assert.Equal("", <-contentTypes)
+
+			resp, err = upper.Invoke(context.Background(), 1, wrapperspb.String("c"), WithJsonEncoding(), WithProtobufEncoding())
+			assert.NoError(err)
+			assert.Equal("c!", resp.GetValue())
+			assert.Equal("", <-contentTypes)
+		})
+	}
+}
+
+func TestProtobufBody_JsonFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	contentTypes := make(chan string, 1)
+	service := protobufService(contentTypes)
+	body, err := proto.Marshal(wrapperspb.String("a"))
+	assert.NoError(err)
+	md := metadata.Pairs(
+		utils.ProxyMethodNameHeader, "upper",
+		utils.BodyContentTypeHeader, ContentTypeProtobuf,
+		utils.BodyAcceptHeader, ContentTypeProtobuf,
+	)
+
+	// response header can't be set without grpc transport
+	res, err := service.Request(metadata.NewIncomingContext(context.Background(), md),
+		&isp.Message{Body: &isp.Message_BytesBody{BytesBody: body}})
+	assert.NoError(err)
+	assert.JSONEq(`{"value":"a!"}`, string(res.GetBytesBody()))
+	assert.Equal(ContentTypeProtobuf, <-contentTypes)
+}
+
+func TestProtobufBody_NilResult(t *testing.T) {
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("nil", func(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			return nil, nil
+		}),
+	})
+	addr, srv := startTestServer(service)
+	defer srv.Stop()
+	rxClient := newTestClient([]structure.AddressConfiguration{addr})
+	defer rxClient.Close()
+
+	// nil proto message is sent as json null without protobuf content type
+	method := NewMethod[*wrapperspb.StringValue, *wrapperspb.StringValue](rxClient, "nil")
+	resp, err := method.Invoke(context.Background(), 1, wrapperspb.String("a"))
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+}
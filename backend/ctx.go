@@ -1,8 +1,16 @@
 package backend
 
-import "google.golang.org/grpc/metadata"
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
 
 type RequestCtx interface {
+	// context of grpc request, it is passed to handler with context.Context param
+	Context() context.Context
+	// replaces context passed to next interceptors and handler, e.g. to add values or set tighter deadline
+	SetContext(ctx context.Context)
 	Method() string
 	Metadata() metadata.MD
 	RequestBody() []byte
@@ -10,9 +18,12 @@ type RequestCtx interface {
 	MappedRequest() interface{}
 	MappedResponse() interface{}
 	Error() error
+	// EndpointDescriptor.Extra of called method
+	Extra() map[string]interface{}
 }
 
 type ctx struct {
+	context        context.Context
 	method         string
 	md             metadata.MD
 	requestBody    []byte
@@ -20,6 +31,18 @@ type ctx struct {
 	mappedRequest  interface{}
 	mappedResponse interface{}
 	err            error
+	extra          map[string]interface{}
+}
+
+func (c *ctx) Context() context.Context {
+	return c.context
+}
+
+func (c *ctx) SetContext(ctx context.Context) {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c.context = ctx
 }
 
 func (c *ctx) Method() string {
@@ -50,7 +73,22 @@ func (c *ctx) Error() error {
 	return c.err
 }
 
-func newCtx() *ctx {
-	return &ctx{}
+func (c *ctx) Extra() map[string]interface{} {
+	return c.extra
+}
+
+// fork returns copy of request state with another context, copy is used by handler which may outlive request
+func (c *ctx) fork(context context.Context) *ctx {
+	return &ctx{
+		context:       context,
+		method:        c.method,
+		md:            c.md,
+		requestBody:   c.requestBody,
+		mappedRequest: c.mappedRequest,
+		extra:         c.extra,
+	}
+}
 
+func newCtx(context context.Context) *ctx {
+	return &ctx{context: context}
 }
@@ -6,15 +6,19 @@ import (
 	"reflect"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	proto "github.com/golang/protobuf/ptypes/struct"
 	"github.com/integration-system/isp-lib/v2/isp"
 	"github.com/integration-system/isp-lib/v2/streaming"
 	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/tracing"
 	"github.com/integration-system/isp-lib/v2/utils"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
 	"github.com/pkg/errors"
+	"github.com/rcrowley/go-metrics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -39,22 +43,32 @@ var (
 )
 
 type DefaultService struct {
-	functions       map[string]function
-	streamConsumers map[string]streamFunction
+	// *handlerSet
+	handlers        atomic.Value
+	updateLock      sync.Mutex
+	metricsRegistry metrics.Registry
 	errHandler      ErrorHandler
-	interceptor     Interceptor
+	interceptors    []Interceptor
 	pps             []PostProcessor
 	validator       Validator
 }
 
 func (df *DefaultService) Request(ctx context.Context, msg *isp.Message) (*isp.Message, error) {
-	c := newCtx()
+	handlers := df.acquireHandlers()
+	defer handlers.release()
+
+	c := newCtx(ctx)
+	var span *tracing.Span
 	defer func() {
 		err := recover()
 		if err != nil {
 			log.WithMetadata(log.Metadata{"method": c.method}).
 				Errorf(stdcodes.ModuleInternalGrpcServiceError, "recovered panic from request: %v", err)
 			debug.PrintStack()
+			// span is finished at the end of request, so it is not finished yet
+			if span != nil {
+				span.Finish(errors.Errorf("recovered panic from request: %v", err))
+			}
 		}
 
 		for _, p := range df.pps {
@@ -62,28 +76,25 @@ func (df *DefaultService) Request(ctx context.Context, msg *isp.Message) (*isp.M
 		}
 	}()
 
-	handler, md, err := df.getHandler(ctx)
+	handler, md, err := getHandler(ctx, handlers)
 	if err != nil {
 		return nil, err
 	}
 
+	spanCtx, span := tracing.StartSpan(tracing.Extract(ctx, metadataGetter(md)), handler.methodName, tracing.ServerSpan)
+	c.SetContext(spanCtx)
 	c.md = md
 	c.method = handler.methodName
+	c.extra = handler.extra
 	c.requestBody = msg.GetBytesBody()
 
 	var dataParam interface{}
 	var result interface{}
-	dataParam, err = handler.unmarshalAndValidateInputData(msg, c, df.validator)
+	dataParam, err = handler.unmarshalAndValidateInputData(msg, c, df.validator, metadataGetter(md)(utils.BodyContentTypeHeader))
 	c.err = err
 	c.mappedRequest = dataParam
 	if err == nil {
-		if df.interceptor != nil {
-			result, err = df.interceptor(c, func() (interface{}, error) {
-				return handler.call(ctx, dataParam, md)
-			})
-		} else {
-			result, err = handler.call(ctx, dataParam, md)
-		}
+		result, err = df.callHandler(c, handler, dataParam, md)
 	}
 
 	if err != nil && df.errHandler != nil {
@@ -98,7 +109,7 @@ func (df *DefaultService) Request(ctx context.Context, msg *isp.Message) (*isp.M
 	} else {
 		msg = emptyBody
 		if result != nil {
-			msg, err = toBytes(result)
+			msg, err = df.encodeResponse(ctx, result, md)
 
 			c.err = err
 			if msg != nil {
@@ -107,15 +118,44 @@ func (df *DefaultService) Request(ctx context.Context, msg *isp.Message) (*isp.M
 		}
 	}
 
+	span.Finish(err)
 	return msg, err
 }
 
+// calls interceptors and handler within endpoint limits
+func (df *DefaultService) callHandler(c *ctx, handler *function, dataParam interface{}, md metadata.MD) (interface{}, error) {
+	proceed := func(requestCtx RequestCtx) (interface{}, error) {
+		return callChain(requestCtx, df.interceptors, func(requestCtx RequestCtx) (interface{}, error) {
+			return handler.call(requestCtx.Context(), dataParam, md)
+		})
+	}
+	if handler.limiter == nil {
+		return proceed(c)
+	}
+
+	release, err := handler.limiter.acquire(c.Context())
+	if err != nil {
+		return nil, err
+	}
+	if timeout := handler.limiter.limits.Timeout; timeout > 0 {
+		// slot is released only after handler returns, even if timeout exceeded,
+		// so handlers which ignore context keep occupying MaxInFlight slots
+		return callWithTimeout(c, timeout, proceed, release)
+	}
+	defer release()
+	return proceed(c)
+}
+
 func (df *DefaultService) RequestStream(stream isp.BackendService_RequestStreamServer) error {
+	handlers := df.acquireHandlers()
+	defer handlers.release()
+
 	ctx := stream.Context()
-	function, md, err := df.getStreamHandler(ctx)
+	function, md, err := getStreamHandler(ctx, handlers)
 	if err != nil {
 		return err
 	}
+	_, span := tracing.StartSpan(tracing.Extract(ctx, metadataGetter(md)), function.methodName, tracing.ServerSpan)
 	func() {
 		defer func() {
 			recovered := recover()
@@ -125,6 +165,7 @@ func (df *DefaultService) RequestStream(stream isp.BackendService_RequestStreamS
 		}()
 		err = function.consume(stream, md)
 	}()
+	span.Finish(err)
 	if err != nil {
 		return handleError(err, function.methodName)
 	}
@@ -136,8 +177,18 @@ func (df *DefaultService) WithErrorHandler(eh ErrorHandler) *DefaultService {
 	return df
 }
 
+// replaces interceptors chain with single interceptor
 func (df *DefaultService) WithInterceptor(interceptor Interceptor) *DefaultService {
-	df.interceptor = interceptor
+	df.interceptors = nil
+	if interceptor != nil {
+		df.interceptors = []Interceptor{interceptor}
+	}
+	return df
+}
+
+// appends interceptors to chain, interceptors are called in order of addition, the first one is the outermost
+func (df *DefaultService) Use(interceptors ...Interceptor) *DefaultService {
+	df.interceptors = append(df.interceptors, interceptors...)
 	return df
 }
 
@@ -146,19 +197,116 @@ func (df *DefaultService) WithPostProcessors(pps ...PostProcessor) *DefaultServi
 	return df
 }
 
+// exposes in-flight and queued requests count of each endpoint in registry
+func (df *DefaultService) WithMetricsRegistry(registry metrics.Registry) *DefaultService {
+	df.updateLock.Lock()
+	defer df.updateLock.Unlock()
+
+	df.metricsRegistry = registry
+	df.registerMetrics(df.currentHandlers())
+	return df
+}
+
+// UpdateDescriptors atomically replaces service handlers, calls started before update are finished with previous handlers,
+// returned drain waits until they are finished
+func (df *DefaultService) UpdateDescriptors(descriptors []structure.EndpointDescriptor) (drain func(ctx context.Context) error, err error) {
+	funcs, streams, err := resolveHandlersByDescriptors(descriptors)
+	if err != nil {
+		return nil, err
+	}
+	descriptorsCopy := make([]structure.EndpointDescriptor, len(descriptors))
+	copy(descriptorsCopy, descriptors)
+	return df.swapHandlers(newHandlerSet(funcs, streams, descriptorsCopy)), nil
+}
+
+// Descriptors returns descriptors of current handlers, it is empty for services created with deprecated GetDefaultService
+func (df *DefaultService) Descriptors() []structure.EndpointDescriptor {
+	descriptors := df.currentHandlers().descriptors
+	result := make([]structure.EndpointDescriptor, len(descriptors))
+	copy(result, descriptors)
+	return result
+}
+
+func (df *DefaultService) swapHandlers(set *handlerSet) func(ctx context.Context) error {
+	df.updateLock.Lock()
+	defer df.updateLock.Unlock()
+
+	prev := df.currentHandlers()
+	reuseLimiters(prev, set)
+	df.unregisterMetrics(prev, set)
+	df.registerMetrics(set)
+	df.handlers.Store(set)
+	prev.retire()
+	return prev.wait
+}
+
+// reuseLimiters moves limiters of methods with unchanged limits to next set,
+// so calls draining on previous set keep occupying in-flight slots
+func reuseLimiters(prev *handlerSet, next *handlerSet) {
+	for method, f := range next.functions {
+		prevF, ok := prev.functions[method]
+		if !ok || prevF.limiter == nil || f.limiter == nil || prevF.limiter.limits != f.limiter.limits {
+			continue
+		}
+		f.limiter = prevF.limiter
+		next.functions[method] = f
+	}
+}
+
+func (df *DefaultService) registerMetrics(set *handlerSet) {
+	if df.metricsRegistry == nil {
+		return
+	}
+	for _, f := range set.functions {
+		if f.limiter != nil {
+			f.limiter.registerMetrics(df.metricsRegistry)
+		}
+	}
+}
+
+// unregisterMetrics removes gauges of endpoints which are absent in next set
+func (df *DefaultService) unregisterMetrics(prev *handlerSet, next *handlerSet) {
+	if df.metricsRegistry == nil {
+		return
+	}
+	for method := range prev.functions {
+		if _, ok := next.functions[method]; !ok {
+			unregisterLimiterMetrics(df.metricsRegistry, method)
+		}
+	}
+}
+
+func (df *DefaultService) currentHandlers() *handlerSet {
+	set, _ := df.handlers.Load().(*handlerSet)
+	if set == nil {
+		return newHandlerSet(nil, nil, nil)
+	}
+	return set
+}
+
+// acquireHandlers returns current handlers registered for call
+func (df *DefaultService) acquireHandlers() *handlerSet {
+	for {
+		set := df.currentHandlers()
+		if set.acquire() {
+			return set
+		}
+	}
+}
+
 func (df *DefaultService) WithValidator(validator Validator) *DefaultService {
 	df.validator = validator
 	return df
 }
 
-func (df *DefaultService) getHandler(ctx context.Context) (*function, metadata.MD, error) {
+func getHandler(ctx context.Context, handlers *handlerSet) (*function, metadata.MD, error) {
 	method, md, err := getMethodName(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	handler, present := df.functions[method]
+	handler, present := handlers.functions[method]
 	if !present {
-		if _, present := df.streamConsumers[method]; present {
+		if _, present := handlers.streamConsumers[method]; present {
 			return nil, nil, status.Errorf(codes.Unimplemented,
 				"Method [%s] accept only binary data. Try add '%s' header",
 				method, utils.ExpectFileHeader,
@@ -169,12 +317,12 @@ func (df *DefaultService) getHandler(ctx context.Context) (*function, metadata.M
 	return &handler, md, nil
 }
 
-func (df *DefaultService) getStreamHandler(ctx context.Context) (*streamFunction, metadata.MD, error) {
+func getStreamHandler(ctx context.Context, handlers *handlerSet) (*streamFunction, metadata.MD, error) {
 	method, md, err := getMethodName(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	handler, present := df.streamConsumers[method]
+	handler, present := handlers.streamConsumers[method]
 	if !present {
 		return nil, nil, status.Errorf(codes.Unimplemented, "Method [%s] is not implemented", method)
 	}
@@ -187,11 +335,11 @@ func GetDefaultService(methodPrefix string, handlersStructs ...interface{}) *Def
 	if err != nil {
 		panic(err)
 	}
-	return &DefaultService{
-		functions:       funcs,
-		streamConsumers: streams,
-		validator:       validate,
+	service := &DefaultService{
+		validator: validate,
 	}
+	service.handlers.Store(newHandlerSet(funcs, streams, nil))
+	return service
 }
 
 func NewDefaultService(descriptors []structure.EndpointDescriptor) *DefaultService {
@@ -199,11 +347,13 @@ func NewDefaultService(descriptors []structure.EndpointDescriptor) *DefaultServi
 	if err != nil {
 		panic(err)
 	}
-	return &DefaultService{
-		functions:       funcs,
-		streamConsumers: streams,
-		validator:       validate,
+	descriptorsCopy := make([]structure.EndpointDescriptor, len(descriptors))
+	copy(descriptorsCopy, descriptors)
+	service := &DefaultService{
+		validator: validate,
 	}
+	service.handlers.Store(newHandlerSet(funcs, streams, descriptorsCopy))
+	return service
 }
 
 // Deprecated
@@ -336,7 +486,20 @@ func resolveHandlersByDescriptors(descriptors []structure.EndpointDescriptor) (m
 
 	for _, descriptor := range descriptors {
 		value := reflect.ValueOf(descriptor.Handler)
-		if f := getStreamConsumer(descriptor.Handler); f != nil {
+		if h, ok := descriptor.Handler.(typedHandler); ok {
+			if _, present := functions[descriptor.Path]; present {
+				return nil, nil, errors.Errorf("duplicate method handlers for method: %s", descriptor.Path)
+			}
+			functions[descriptor.Path] = function{
+				methodName:   descriptor.Path,
+				extra:        descriptor.Extra,
+				typed:        h,
+				limiter:      newEndpointLimiter(descriptor.Path, descriptor.Limits),
+				dataParamNum: -1,
+				mdParamNum:   -1,
+				ctxParamNum:  -1,
+			}
+		} else if f := getStreamConsumer(descriptor.Handler); f != nil {
 			if _, present := streamHandlers[descriptor.Path]; present {
 				return nil, nil, errors.Errorf("duplicate method handlers for method: %s", descriptor.Path)
 			}
@@ -354,6 +517,8 @@ func resolveHandlersByDescriptors(descriptors []structure.EndpointDescriptor) (m
 				return nil, nil, errors.Errorf("duplicate method handlers for method: %s", descriptor.Path)
 			}
 			f.methodName = descriptor.Path
+			f.extra = descriptor.Extra
+			f.limiter = newEndpointLimiter(descriptor.Path, descriptor.Limits)
 			functions[descriptor.Path] = f
 		}
 	}
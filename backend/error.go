@@ -0,0 +1,224 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/utils"
+	epb "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+type PreconditionViolation struct {
+	Type        string
+	Subject     string
+	Description string
+}
+
+// Error is handler error with business error code and typed details which are transferred to client as grpc status details.
+// Cause is not sent to client, it is logged by server.
+// Errors are comparable with errors.Is by Code and ErrorCode, so sentinel errors may be declared and checked on both sides:
+//
+//	var ErrUserNotFound = backend.NewError(codes.NotFound, "USER_NOT_FOUND", "user not found")
+type Error struct {
+	Code                   codes.Code
+	ErrorCode              string
+	Message                string
+	FieldViolations        []FieldViolation
+	PreconditionViolations []PreconditionViolation
+	RetryDelay             time.Duration
+	Metadata               map[string]string
+
+	cause  error
+	status *status.Status
+}
+
+func NewError(code codes.Code, errorCode string, message string) *Error {
+	return &Error{
+		Code:      code,
+		ErrorCode: errorCode,
+		Message:   message,
+	}
+}
+
+// NewValidationError creates InvalidArgument error with field violations
+func NewValidationError(violations ...FieldViolation) *Error {
+	e := NewError(codes.InvalidArgument, "", utils.ValidationError)
+	e.FieldViolations = violations
+	return e
+}
+
+func (e *Error) Error() string {
+	code := e.ErrorCode
+	if code == "" {
+		code = e.Code.String()
+	}
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", code, e.Message, e.cause)
+	}
+	return fmt.Sprintf("%s: %s", code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is *Error with the same Code and ErrorCode
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code && e.ErrorCode == t.ErrorCode
+}
+
+// Wrap returns copy of error with cause, cause is logged and not sent to client
+func (e *Error) Wrap(cause error) *Error {
+	c := e.copy()
+	c.cause = cause
+	return c
+}
+
+// WithMessage returns copy of error with message
+func (e *Error) WithMessage(format string, args ...interface{}) *Error {
+	c := e.copy()
+	c.Message = fmt.Sprintf(format, args...)
+	return c
+}
+
+// WithFieldViolation returns copy of error with added field violation
+func (e *Error) WithFieldViolation(field, description string) *Error {
+	c := e.copy()
+	c.FieldViolations = append(c.FieldViolations, FieldViolation{Field: field, Description: description})
+	return c
+}
+
+// WithPreconditionViolation returns copy of error with added precondition failure
+func (e *Error) WithPreconditionViolation(typ, subject, description string) *Error {
+	c := e.copy()
+	c.PreconditionViolations = append(c.PreconditionViolations, PreconditionViolation{
+		Type:        typ,
+		Subject:     subject,
+		Description: description,
+	})
+	return c
+}
+
+// WithRetryDelay returns copy of error which suggests client to retry after delay
+func (e *Error) WithRetryDelay(delay time.Duration) *Error {
+	c := e.copy()
+	c.RetryDelay = delay
+	return c
+}
+
+// WithMetadata returns copy of error with added metadata entry
+func (e *Error) WithMetadata(key, value string) *Error {
+	c := e.copy()
+	c.Metadata = make(map[string]string, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		c.Metadata[k] = v
+	}
+	c.Metadata[key] = value
+	return c
+}
+
+func (e *Error) HttpStatus() int {
+	return utils.CodeToHttpStatus(e.Code)
+}
+
+// GRPCStatus converts error to grpc status with details, it is used by status.FromError and status.Code
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status != nil {
+		return e.status
+	}
+
+	st := status.New(e.Code, e.Message)
+	if e.ErrorCode != "" || len(e.Metadata) > 0 {
+		if ds, err := st.WithDetails(&epb.ErrorInfo{Reason: e.ErrorCode, Metadata: e.Metadata}); err == nil {
+			st = ds
+		}
+	}
+	if len(e.FieldViolations) > 0 {
+		violations := make([]*epb.BadRequest_FieldViolation, len(e.FieldViolations))
+		for i, v := range e.FieldViolations {
+			violations[i] = &epb.BadRequest_FieldViolation{Field: v.Field, Description: v.Description}
+		}
+		if ds, err := st.WithDetails(&epb.BadRequest{FieldViolations: violations}); err == nil {
+			st = ds
+		}
+	}
+	if len(e.PreconditionViolations) > 0 {
+		violations := make([]*epb.PreconditionFailure_Violation, len(e.PreconditionViolations))
+		for i, v := range e.PreconditionViolations {
+			violations[i] = &epb.PreconditionFailure_Violation{Type: v.Type, Subject: v.Subject, Description: v.Description}
+		}
+		if ds, err := st.WithDetails(&epb.PreconditionFailure{Violations: violations}); err == nil {
+			st = ds
+		}
+	}
+	if e.RetryDelay > 0 {
+		if ds, err := st.WithDetails(&epb.RetryInfo{RetryDelay: durationpb.New(e.RetryDelay)}); err == nil {
+			st = ds
+		}
+	}
+	return st
+}
+
+func (e *Error) copy() *Error {
+	c := *e
+	c.status = nil
+	c.FieldViolations = append([]FieldViolation(nil), e.FieldViolations...)
+	c.PreconditionViolations = append([]PreconditionViolation(nil), e.PreconditionViolations...)
+	return &c
+}
+
+// FromStatus converts grpc status with details to *Error, original status is preserved
+func FromStatus(st *status.Status) *Error {
+	e := &Error{
+		Code:    st.Code(),
+		Message: st.Message(),
+		status:  st,
+	}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *epb.ErrorInfo:
+			e.ErrorCode = d.GetReason()
+			e.Metadata = d.GetMetadata()
+		case *epb.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				e.FieldViolations = append(e.FieldViolations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *epb.PreconditionFailure:
+			for _, v := range d.GetViolations() {
+				e.PreconditionViolations = append(e.PreconditionViolations, PreconditionViolation{
+					Type:        v.GetType(),
+					Subject:     v.GetSubject(),
+					Description: v.GetDescription(),
+				})
+			}
+		case *epb.RetryInfo:
+			e.RetryDelay = d.GetRetryDelay().AsDuration()
+		}
+	}
+	return e
+}
+
+// AsError finds *Error in err chain or converts grpc status error, returns false for other errors
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	if st, ok := status.FromError(err); ok && st != nil {
+		return FromStatus(st), true
+	}
+	return nil, false
+}
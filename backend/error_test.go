@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUserNotFound = NewError(codes.NotFound, "USER_NOT_FOUND", "user not found")
+
+func TestErrorConversion(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("user", func(ctx context.Context, req struct{}) (struct{}, error) {
+			return struct{}{}, pkgerrors.WithStack(errUserNotFound.
+				WithFieldViolation("id", "unknown id").
+				WithPreconditionViolation("STATE", "user", "deleted").
+				WithRetryDelay(time.Second).
+				WithMetadata("id", "1").
+				Wrap(io.EOF))
+		}),
+	})
+	_, err := requestMethod(service, "user")
+	assert.Equal(codes.NotFound, status.Code(err))
+	assert.Equal("user not found", status.Convert(err).Message())
+
+	// client side
+	e := FromStatus(status.Convert(err))
+	assert.True(errors.Is(e, errUserNotFound))
+	assert.False(errors.Is(e, NewError(codes.NotFound, "OTHER", "")))
+	assert.Equal("USER_NOT_FOUND", e.ErrorCode)
+	assert.Equal([]FieldViolation{{Field: "id", Description: "unknown id"}}, e.FieldViolations)
+	assert.Equal([]PreconditionViolation{{Type: "STATE", Subject: "user", Description: "deleted"}}, e.PreconditionViolations)
+	assert.Equal(time.Second, e.RetryDelay)
+	assert.Equal(map[string]string{"id": "1"}, e.Metadata)
+	assert.Equal(http.StatusNotFound, e.HttpStatus())
+	assert.Equal(status.Convert(err).Proto(), e.GRPCStatus().Proto())
+
+	// sentinel is not modified
+	assert.Empty(errUserNotFound.FieldViolations)
+	assert.Nil(errUserNotFound.Unwrap())
+}
+
+func TestAsError(t *testing.T) {
+	assert := assert.New(t)
+
+	wrapped := errUserNotFound.Wrap(io.EOF)
+	e, ok := AsError(pkgerrors.WithMessage(wrapped, "find user"))
+	assert.True(ok)
+	assert.Same(wrapped, e)
+	assert.True(errors.Is(e, io.EOF))
+
+	e, ok = AsError(status.Error(codes.Unavailable, "unavailable"))
+	assert.True(ok)
+	assert.Equal(codes.Unavailable, e.Code)
+
+	_, ok = AsError(io.EOF)
+	assert.False(ok)
+
+	_, mustLog := ResolveError(wrapped)
+	assert.True(mustLog)
+	_, mustLog = ResolveError(errUserNotFound)
+	assert.False(mustLog)
+}
+
+type validatedItem struct {
+	Name string `valid:"required~Required"`
+}
+
+func TestValidationViolations(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("items", func(ctx context.Context, req []validatedItem) (int, error) {
+			return len(req), nil
+		}),
+	})
+	cli := NewLoopbackClient(service)
+	err := cli.Invoke("items", 1, []validatedItem{{}, {Name: "a"}, {}}, nil)
+	e := FromStatus(status.Convert(err))
+	assert.Equal(codes.InvalidArgument, e.Code)
+	assert.Equal([]FieldViolation{
+		{Field: "[0].name", Description: "Required"},
+		{Field: "[2].name", Description: "Required"},
+	}, e.FieldViolations)
+}
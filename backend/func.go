@@ -21,14 +21,22 @@ type function struct {
 	paramsCount   int
 	fun           reflect.Value
 	methodName    string
+	extra         map[string]interface{}
+	// not nil for handlers created with Handle
+	typed typedHandler
+	// nil for handlers resolved without descriptors
+	limiter *endpointLimiter
 }
 
-func (f function) unmarshalAndValidateInputData(msg *isp.Message, ctx *ctx, validator Validator) (interface{}, error) {
+func (f function) unmarshalAndValidateInputData(msg *isp.Message, ctx *ctx, validator Validator, contentType string) (interface{}, error) {
 	var dataParam interface{}
-	if f.dataParamType != nil {
-		val := reflect.New(f.dataParamType)
-		dataParam = val.Interface()
-		err := readBody(msg, dataParam)
+	if f.typed != nil || f.dataParamType != nil {
+		if f.typed != nil {
+			dataParam = f.typed.newRequest()
+		} else {
+			dataParam = reflect.New(f.dataParamType).Interface()
+		}
+		err := decodeBody(msg, dataParam, contentType)
 		if err != nil {
 			return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %s", err)
 		}
@@ -42,7 +50,10 @@ func (f function) unmarshalAndValidateInputData(msg *isp.Message, ctx *ctx, vali
 	return nil, nil
 }
 
-func (f function) call(ctx context.Context, dataParam interface{}, md metadata.MD) (_ interface{}, err error) { // todo ctx context.Context
+func (f function) call(ctx context.Context, dataParam interface{}, md metadata.MD) (_ interface{}, err error) {
+	if f.typed != nil {
+		return f.typed.call(ctx, dataParam)
+	}
 	defer func() {
 		recovered := recover()
 		if recovered != nil {
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/pkg/errors"
+)
+
+// implemented by handlers created with Handle, they are called without reflection
+type typedHandler interface {
+	// returns pointer to new request value
+	newRequest() interface{}
+	call(ctx context.Context, req interface{}) (interface{}, error)
+	types() (req reflect.Type, resp reflect.Type)
+}
+
+type handlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+func (f handlerFunc[Req, Resp]) newRequest() interface{} {
+	return new(Req)
+}
+
+func (f handlerFunc[Req, Resp]) types() (reflect.Type, reflect.Type) {
+	return reflect.TypeOf((*Req)(nil)).Elem(), reflect.TypeOf((*Resp)(nil)).Elem()
+}
+
+func (f handlerFunc[Req, Resp]) call(ctx context.Context, req interface{}) (_ interface{}, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = errors.WithStack(errors.Errorf("recovered panic from handler: %v", recovered))
+		}
+	}()
+	return f(ctx, *req.(*Req))
+}
+
+type EndpointOption func(descriptor *structure.EndpointDescriptor)
+
+// method is available only for other modules, not for external clients
+func InnerEndpoint() EndpointOption {
+	return func(descriptor *structure.EndpointDescriptor) {
+		descriptor.Inner = true
+	}
+}
+
+func RequireUserAuth() EndpointOption {
+	return func(descriptor *structure.EndpointDescriptor) {
+		descriptor.UserAuthRequired = true
+	}
+}
+
+func WithExtra(key string, value interface{}) EndpointOption {
+	return func(descriptor *structure.EndpointDescriptor) {
+		if descriptor.Extra == nil {
+			descriptor.Extra = make(map[string]interface{})
+		}
+		descriptor.Extra[key] = value
+	}
+}
+
+// Handle creates endpoint descriptor with typed handler, request body is unmarshalled to Req and validated,
+// handler signature is checked at compile time and handler is called without reflection. Example:
+// backend.Handle("module/object/get", func(ctx context.Context, req GetRequest) (*Object, error) {...})
+func Handle[Req, Resp any](path string, handler func(ctx context.Context, req Req) (Resp, error), opts ...EndpointOption) structure.EndpointDescriptor {
+	descriptor := structure.EndpointDescriptor{
+		Path:    path,
+		Handler: handlerFunc[Req, Resp](handler),
+	}
+	for _, opt := range opts {
+		opt(&descriptor)
+	}
+	return descriptor
+}
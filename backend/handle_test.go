@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type sumRequest struct {
+	A int `valid:"required"`
+	B int
+}
+
+type sumResponse struct {
+	Sum int
+}
+
+func TestHandle(t *testing.T) {
+	assert := assert.New(t)
+
+	descriptor := Handle("math/sum", func(ctx context.Context, req sumRequest) (*sumResponse, error) {
+		return &sumResponse{Sum: req.A + req.B}, nil
+	}, InnerEndpoint(), WithExtra("audit", true))
+	assert.Equal("math/sum", descriptor.Path)
+	assert.True(descriptor.Inner)
+	assert.Equal(map[string]interface{}{"audit": true}, descriptor.Extra)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		descriptor,
+		Handle("panic", func(ctx context.Context, req struct{}) (struct{}, error) {
+			panic("handler panic")
+		}),
+	})
+	request := func(method string, body string) (*isp.Message, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(utils.ProxyMethodNameHeader, method))
+		return service.Request(ctx, &isp.Message{Body: &isp.Message_BytesBody{BytesBody: []byte(body)}})
+	}
+
+	msg, err := request("math/sum", `{"a":1,"b":2}`)
+	assert.NoError(err)
+	assert.JSONEq(`{"sum":3}`, string(msg.GetBytesBody()))
+
+	_, err = request("math/sum", `{"b":2}`)
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = request("panic", `{}`)
+	assert.Equal(codes.Internal, status.Code(err))
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+)
+
+// handlerSet is immutable set of service handlers, it is replaced as a whole on update.
+// Calls acquire set for the whole request, so retired set can be drained
+type handlerSet struct {
+	functions       map[string]function
+	streamConsumers map[string]streamFunction
+	descriptors     []structure.EndpointDescriptor
+
+	lock    sync.Mutex
+	active  int
+	retired bool
+	drained chan struct{}
+}
+
+func newHandlerSet(functions map[string]function, streams map[string]streamFunction, descriptors []structure.EndpointDescriptor) *handlerSet {
+	return &handlerSet{
+		functions:       functions,
+		streamConsumers: streams,
+		descriptors:     descriptors,
+		drained:         make(chan struct{}),
+	}
+}
+
+// acquire registers call, returns false if set is retired and new one must be used
+func (s *handlerSet) acquire() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.retired {
+		return false
+	}
+	s.active++
+	return true
+}
+
+func (s *handlerSet) release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.active--
+	if s.retired && s.active == 0 {
+		close(s.drained)
+	}
+}
+
+func (s *handlerSet) retire() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retired = true
+	if s.active == 0 {
+		close(s.drained)
+	}
+}
+
+// wait waits until all calls of retired set are finished or ctx is done
+func (s *handlerSet) wait(ctx context.Context) error {
+	select {
+	case <-s.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
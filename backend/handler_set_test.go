@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type routesDeclaratorMock struct {
+	calls chan []string
+	svc   *DefaultService
+}
+
+func (d *routesDeclaratorMock) DeclareRoutes() {
+	paths := make([]string, 0)
+	for _, descriptor := range d.svc.Descriptors() {
+		paths = append(paths, descriptor.Path)
+	}
+	d.calls <- paths
+}
+
+func TestDefaultService_UpdateDescriptors(t *testing.T) {
+	assert := assert.New(t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("version", func(ctx context.Context, _ struct{}) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		}),
+	})
+	cli := NewLoopbackClient(service)
+
+	oldResult := make(chan int)
+	go func() {
+		var version int
+		err := cli.Invoke("version", 1, struct{}{}, &version)
+		assert.NoError(err)
+		oldResult <- version
+	}()
+	<-started
+
+	declarator := &routesDeclaratorMock{calls: make(chan []string, 1), svc: service}
+	srv := &GrpcServer{service: service}
+	srv.SetRoutesDeclarator(declarator)
+	updated := make(chan error)
+	go func() {
+		updated <- srv.UpdateEndpoints(context.Background(), []structure.EndpointDescriptor{
+			Handle("version2", func(ctx context.Context, _ struct{}) (int, error) {
+				return 2, nil
+			}),
+		})
+	}()
+	assert.Equal([]string{"version2"}, <-declarator.calls)
+
+	// new calls use new handlers while previous call is in flight
+	var version int
+	assert.NoError(cli.Invoke("version2", 1, struct{}{}, &version))
+	assert.Equal(2, version)
+	err := cli.Invoke("version", 1, struct{}{}, &version)
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	select {
+	case <-updated:
+		assert.Fail("update must wait for in-flight call")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	assert.Equal(1, <-oldResult)
+	assert.NoError(<-updated)
+}
+
+func TestDefaultService_UpdateDescriptorsDrainTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("slow", func(ctx context.Context, _ struct{}) (struct{}, error) {
+			close(started)
+			<-release
+			return struct{}{}, nil
+		}),
+	})
+	go func() {
+		_ = NewLoopbackClient(service).Invoke("slow", 1, struct{}{}, nil)
+	}()
+	<-started
+
+	drain, err := service.UpdateDescriptors(nil)
+	assert.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(drain(ctx), context.DeadlineExceeded)
+	assert.Empty(service.Descriptors())
+}
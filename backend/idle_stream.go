@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// idleTimeoutStream cancels stream if there are no sent or received messages during timeout
+type idleTimeoutStream struct {
+	isp.BackendService_RequestStreamClient
+	timeout time.Duration
+	timer   *time.Timer
+	expired int32
+}
+
+func newIdleTimeoutStream(stream isp.BackendService_RequestStreamClient, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutStream {
+	s := &idleTimeoutStream{
+		BackendService_RequestStreamClient: stream,
+		timeout:                            timeout,
+	}
+	s.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&s.expired, 1)
+		cancel()
+	})
+	return s
+}
+
+func (s *idleTimeoutStream) Send(msg *isp.Message) error {
+	s.timer.Reset(s.timeout)
+	err := s.BackendService_RequestStreamClient.Send(msg)
+	s.timer.Reset(s.timeout)
+	return s.resolveError(err)
+}
+
+func (s *idleTimeoutStream) Recv() (*isp.Message, error) {
+	s.timer.Reset(s.timeout)
+	msg, err := s.BackendService_RequestStreamClient.Recv()
+	s.timer.Reset(s.timeout)
+	return msg, s.resolveError(err)
+}
+
+func (s *idleTimeoutStream) stop() {
+	s.timer.Stop()
+}
+
+// replaces error caused by idle timeout cancellation with DeadlineExceeded
+func (s *idleTimeoutStream) resolveError(err error) error {
+	if err != nil && atomic.LoadInt32(&s.expired) == 1 && status.Code(err) == codes.Canceled {
+		return status.Errorf(codes.DeadlineExceeded, "stream is idle more than %s", s.timeout)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/streaming"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const streamMethodPath = "stream/path"
+
+func TestRxGrpcClient_InvokeStreamTimeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	addr, srv := startHandlerServer(streamMethodPath, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if msg.GetBytesBody() == nil {
+				time.Sleep(300 * time.Millisecond)
+			}
+			if err := stream.Send(&isp.Message{Body: &isp.Message_BytesBody{BytesBody: []byte(md.Get("x-test")[0])}}); err != nil {
+				return err
+			}
+		}
+	})
+	defer srv.Stop()
+	cli := newTestClient([]structure.AddressConfiguration{addr})
+	defer cli.Close()
+
+	exchange := func(stream streaming.DuplexMessageStream, msg *isp.Message) (string, error) {
+		if err := stream.Send(msg); err != nil {
+			return "", err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return "", err
+		}
+		return string(resp.GetBytesBody()), nil
+	}
+	bytesMsg := &isp.Message{Body: &isp.Message_BytesBody{BytesBody: []byte("data")}}
+
+	// active stream lasts longer than idle timeout
+	err := cli.InvokeStream(streamMethodPath, 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		for i := 0; i < 5; i++ {
+			resp, err := exchange(stream, bytesMsg)
+			if err != nil {
+				return err
+			}
+			assert.Equal("value", resp)
+			time.Sleep(30 * time.Millisecond)
+		}
+		return stream.(interface{ CloseSend() error }).CloseSend()
+	}, WithIdleTimeout(100*time.Millisecond), WithMetadata(metadata.Pairs("x-test", "value")))
+	assert.NoError(err)
+
+	// server does not respond during idle timeout
+	err = cli.InvokeStream(streamMethodPath, 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		_, err := exchange(stream, emptyBody)
+		return err
+	}, WithIdleTimeout(50*time.Millisecond), WithMetadata(metadata.Pairs("x-test", "value")))
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	// total timeout
+	err = cli.InvokeStream(streamMethodPath, 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		_, err := exchange(stream, emptyBody)
+		return err
+	}, WithTimeout(50*time.Millisecond), WithMetadata(metadata.Pairs("x-test", "value")))
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	// canceled by caller
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err = cli.InvokeStream(streamMethodPath, 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		_, err := exchange(stream, emptyBody)
+		return err
+	}, WithContext(ctx), WithMetadata(metadata.Pairs("x-test", "value")))
+	assert.Equal(codes.Canceled, status.Code(err))
+}
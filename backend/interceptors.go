@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/tracing"
+	log "github.com/integration-system/isp-log"
+	"github.com/integration-system/isp-log/stdcodes"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestLogEvent = 89
+)
+
+// ChainInterceptors composes interceptors into one, the first one is the outermost
+func ChainInterceptors(interceptors ...Interceptor) Interceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		if link, ok := ctx.(*chainLink); ok {
+			// the rest of outer chain gets ctx forked by composed interceptors
+			return callChain(link.RequestCtx, interceptors, link.next)
+		}
+		return callChain(ctx, interceptors, func(RequestCtx) (interface{}, error) {
+			return proceed()
+		})
+	}
+}
+
+// chainLink is RequestCtx passed to interceptor of chain, it holds the rest of chain,
+// so TimeoutInterceptor can pass forked ctx to it
+type chainLink struct {
+	RequestCtx
+	next func(ctx RequestCtx) (interface{}, error)
+}
+
+// callChain passes ctx to interceptors and handler, ctx forked by TimeoutInterceptor is passed to the rest of chain
+func callChain(ctx RequestCtx, interceptors []Interceptor, handler func(ctx RequestCtx) (interface{}, error)) (interface{}, error) {
+	if len(interceptors) == 0 {
+		return handler(ctx)
+	}
+	next := func(ctx RequestCtx) (interface{}, error) {
+		return callChain(ctx, interceptors[1:], handler)
+	}
+	return interceptors[0](&chainLink{RequestCtx: ctx, next: next}, func() (interface{}, error) {
+		return next(ctx)
+	})
+}
+
+// ForMethods applies interceptor only to methods with one of path prefixes
+func ForMethods(interceptor Interceptor, pathPrefixes ...string) Interceptor {
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		for _, prefix := range pathPrefixes {
+			if strings.HasPrefix(ctx.Method(), prefix) {
+				return interceptor(ctx, proceed)
+			}
+		}
+		return proceed()
+	}
+}
+
+// ForExtra applies interceptor only to methods which EndpointDescriptor.Extra contains flag with not false value
+func ForExtra(interceptor Interceptor, flag string) Interceptor {
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		value, ok := ctx.Extra()[flag]
+		if !ok || value == nil || value == false {
+			return proceed()
+		}
+		return interceptor(ctx, proceed)
+	}
+}
+
+// LoggingInterceptor logs method, elapsed time, request id and error of each request, successful requests are logged with debug level
+func LoggingInterceptor() Interceptor {
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		start := time.Now()
+		result, err := proceed()
+		logger := log.WithMetadata(log.Metadata{
+			"method":    ctx.Method(),
+			"elapsed":   time.Since(start).String(),
+			"requestId": tracing.RequestId(ctx.Context()),
+		})
+		if err != nil {
+			logger.Warnf(requestLogEvent, "request failed: %v", err)
+		} else {
+			logger.Debug(requestLogEvent, "request handled")
+		}
+		return result, err
+	}
+}
+
+// RecoveryInterceptor converts panics from inner interceptors and handler to error
+func RecoveryInterceptor() Interceptor {
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (result interface{}, err error) {
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				log.WithMetadata(log.Metadata{"method": ctx.Method()}).
+					Errorf(stdcodes.ModuleInternalGrpcServiceError, "recovered panic from request: %v", recovered)
+				result, err = nil, errors.WithStack(errors.Errorf("recovered panic from request: %v", recovered))
+			}
+		}()
+		return proceed()
+	}
+}
+
+// TimeoutInterceptor passes context with deadline to the next interceptors and handler and returns DeadlineExceeded error
+// if request is not handled in timeout. Handler which ignores context is not interrupted and its result is discarded,
+// the rest of chain gets own copy of RequestCtx, so changes made by it are not visible to outer interceptors
+// and post-processors. Called outside of chain, interceptor can't pass context with deadline to proceed
+func TimeoutInterceptor(timeout time.Duration) Interceptor {
+	return func(requestCtx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		if link, ok := requestCtx.(*chainLink); ok {
+			return callWithTimeout(link.RequestCtx, timeout, link.next, nil)
+		}
+		return callWithTimeout(requestCtx, timeout, func(RequestCtx) (interface{}, error) {
+			return proceed()
+		}, nil)
+	}
+}
+
+// calls proceed with forked ctx in separate goroutine, ctx is not changed. onDone is called after proceed returns
+// even if timeout exceeded
+func callWithTimeout(ctx RequestCtx, timeout time.Duration, proceed func(ctx RequestCtx) (interface{}, error), onDone func()) (interface{}, error) {
+	type response struct {
+		result interface{}
+		err    error
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx.Context(), timeout)
+	defer cancel()
+	forked := forkCtx(ctx, timeoutCtx)
+
+	responseCh := make(chan response, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				responseCh <- response{err: errors.WithStack(errors.Errorf("recovered panic from request: %v", recovered))}
+			}
+			if onDone != nil {
+				onDone()
+			}
+		}()
+		result, err := proceed(forked)
+		responseCh <- response{result: result, err: err}
+	}()
+
+	select {
+	case resp := <-responseCh:
+		return resp.result, resp.err
+	case <-timeoutCtx.Done():
+		if timeoutCtx.Err() == context.Canceled {
+			return nil, status.Errorf(codes.Canceled, "Method [%s] canceled", ctx.Method())
+		}
+		return nil, status.Errorf(codes.DeadlineExceeded, "Method [%s] timed out after %s", ctx.Method(), timeout)
+	}
+}
+
+// forkCtx returns ctx with another context, request state of not own RequestCtx implementation is shared
+func forkCtx(c RequestCtx, context context.Context) RequestCtx {
+	if cc, ok := c.(*ctx); ok {
+		return cc.fork(context)
+	}
+	return &contextOverride{RequestCtx: c, context: context}
+}
+
+type contextOverride struct {
+	RequestCtx
+	context context.Context
+}
+
+func (c *contextOverride) Context() context.Context {
+	return c.context
+}
+
+func (c *contextOverride) SetContext(ctx context.Context) {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c.context = ctx
+}
+
+// AuthInterceptor rejects request if authenticate returns error, not grpc errors are converted to Unauthenticated,
+// usually combined with ForExtra or ForMethods
+func AuthInterceptor(authenticate func(ctx RequestCtx) error) Interceptor {
+	return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		if err := authenticate(ctx); err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return proceed()
+	}
+}
@@ -0,0 +1,243 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/tracing"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func requestMethod(service *DefaultService, method string) (*isp.Message, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(utils.ProxyMethodNameHeader, method))
+	return service.Request(ctx, emptyBody)
+}
+
+func TestInterceptorsChain(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := make([]string, 0)
+	record := func(name string) Interceptor {
+		return func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			calls = append(calls, name)
+			return proceed()
+		}
+	}
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "public/method", Handler: func() string { return "ok" }},
+		{Path: "private/method", Handler: func() string { return "ok" }, Extra: map[string]interface{}{"audit": true}},
+		{Path: "private/slow", Handler: func() string { time.Sleep(time.Second); return "ok" }},
+		{Path: "private/panic", Handler: func() { panic("handler panic") }},
+	}).Use(
+		record("first"),
+		ForMethods(record("private"), "private/"),
+		ForExtra(record("audit"), "audit"),
+		ForMethods(AuthInterceptor(func(ctx RequestCtx) error {
+			if len(ctx.Metadata().Get("token")) == 0 {
+				return errors.New("token required")
+			}
+			return nil
+		}), "private/panic"),
+		ForMethods(TimeoutInterceptor(50*time.Millisecond), "private/slow"),
+		RecoveryInterceptor(),
+		record("last"),
+	)
+
+	_, err := requestMethod(service, "public/method")
+	assert.NoError(err)
+	assert.Equal([]string{"first", "last"}, calls)
+
+	calls = calls[:0]
+	_, err = requestMethod(service, "private/method")
+	assert.NoError(err)
+	assert.Equal([]string{"first", "private", "audit", "last"}, calls)
+
+	_, err = requestMethod(service, "private/panic")
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	// slow handler keeps running after timeout, so it is called last
+	_, err = requestMethod(service, "private/slow")
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestTimeoutInterceptorOrphanedHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	release, finished := make(chan struct{}), make(chan struct{})
+	var ppErr, ppCtxErr error
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "slow", Handler: func(ctx context.Context) string {
+			<-release
+			return "late"
+		}},
+	}).Use(
+		TimeoutInterceptor(20*time.Millisecond),
+		func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			defer close(finished)
+			result, err := proceed()
+			// orphaned chain still uses its own ctx after timeout
+			ctx.SetContext(context.WithValue(ctx.Context(), testUserKey{}, "orphan"))
+			_ = ctx.Error()
+			return result, err
+		},
+	).WithPostProcessors(func(ctx RequestCtx) {
+		ppErr = ctx.Error()
+		ppCtxErr = ctx.Context().Err()
+	})
+
+	_, err := requestMethod(service, "slow")
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(codes.DeadlineExceeded, status.Code(ppErr))
+	// post-processors get request context, not canceled timeout one
+	assert.NoError(ppCtxErr)
+
+	close(release)
+	<-finished
+}
+
+func TestWithInterceptorReplacesChain(t *testing.T) {
+	called := false
+	service := NewDefaultService([]structure.EndpointDescriptor{{Path: "method", Handler: func() {}}}).
+		Use(func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			t.Error("replaced interceptor is called")
+			return proceed()
+		}).
+		WithInterceptor(func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			called = true
+			return proceed()
+		})
+	_, err := requestMethod(service, "method")
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+type testUserKey struct{}
+
+func TestRequestCtxContext(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "user", Handler: func(ctx context.Context) (string, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				return "", errors.New("deadline is not set")
+			}
+			return ctx.Value(testUserKey{}).(string), nil
+		}},
+		{Path: "wait", Handler: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}).Use(
+		func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			ctx.SetContext(context.WithValue(ctx.Context(), testUserKey{}, "admin"))
+			return proceed()
+		},
+		TimeoutInterceptor(100*time.Millisecond),
+	)
+
+	msg, err := requestMethod(service, "user")
+	assert.NoError(err)
+	assert.Equal(`"admin"`, string(msg.GetBytesBody()))
+
+	start := time.Now()
+	_, err = requestMethod(service, "wait")
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestChainInterceptorsTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	deadlineInterceptor := func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		if _, ok := ctx.Context().Deadline(); !ok {
+			return nil, errors.New("deadline is not set")
+		}
+		return proceed()
+	}
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "method", Handler: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("handler deadline is not set")
+			}
+			return nil
+		}},
+	}).Use(
+		ChainInterceptors(TimeoutInterceptor(time.Second), deadlineInterceptor),
+		deadlineInterceptor,
+	)
+
+	_, err := requestMethod(service, "method")
+	assert.NoError(err)
+
+	// outside of chain outer ctx is not changed
+	outer := newCtx(context.Background())
+	_, err = TimeoutInterceptor(time.Second)(outer, func() (interface{}, error) {
+		return nil, nil
+	})
+	assert.NoError(err)
+	_, ok := outer.Context().Deadline()
+	assert.False(ok)
+}
+
+func TestRequestTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	var handlerCtx context.Context
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("traced", func(ctx context.Context, req struct{}) (struct{}, error) {
+			handlerCtx = ctx
+			return struct{}{}, nil
+		}),
+	})
+	exported := make([]*tracing.Span, 0)
+	tracing.SetExporter(tracing.ExporterFunc(func(span *tracing.Span) {
+		exported = append(exported, span)
+	}))
+	defer tracing.SetExporter(nil)
+
+	md := metadata.Pairs(
+		utils.ProxyMethodNameHeader, "traced",
+		tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		tracing.RequestIdHeader, "req-1",
+	)
+	_, err := service.Request(metadata.NewIncomingContext(context.Background(), md), emptyBody)
+	assert.NoError(err)
+
+	sc, ok := tracing.FromContext(handlerCtx)
+	assert.True(ok)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceId)
+	assert.Equal("req-1", sc.RequestId)
+	if assert.Len(exported, 1) {
+		assert.Equal("traced", exported[0].Name)
+		assert.Equal(tracing.ServerSpan, exported[0].Kind)
+		assert.Equal("00f067aa0ba902b7", exported[0].ParentSpanId)
+	}
+}
+
+func TestRequestTracingPanic(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{{Path: "method", Handler: func() {}}}).
+		Use(func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+			panic("interceptor panic")
+		})
+	exported := make([]*tracing.Span, 0)
+	tracing.SetExporter(tracing.ExporterFunc(func(span *tracing.Span) {
+		exported = append(exported, span)
+	}))
+	defer tracing.SetExporter(nil)
+
+	_, _ = requestMethod(service, "method")
+	if assert.Len(exported, 1) {
+		assert.Equal("method", exported[0].Name)
+		assert.EqualError(exported[0].Err, "recovered panic from request: interceptor panic")
+	}
+}
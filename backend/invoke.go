@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/streaming"
+	"github.com/integration-system/isp-lib/v2/tracing"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// invoke prepares call context with timeout, trace and metadata, converts request and response bodies,
+// request is sent as json unless protobuf encoding is requested, protobuf response is accepted for proto messages
+// unless json encoding is forced, grpc status errors are converted to *Error
+func invoke(method string, callerId int, requestBody, responsePointer interface{}, options *invokeOpts,
+	request func(ctx context.Context, msg *isp.Message) (*response, error)) (err error) {
+	spanCtx, span := tracing.StartSpan(options.ctx, method, tracing.ClientSpan)
+	defer func() {
+		span.Finish(err)
+	}()
+
+	msg, contentType, err := encodeBody(requestBody, options.protobufEncoding && !options.jsonEncoding)
+	if err != nil {
+		return err
+	}
+
+	md := options.md
+	md.Set(utils.ProxyMethodNameHeader, method)
+	md.Set(utils.ApplicationIdHeader, strconv.Itoa(callerId))
+	if contentType == ContentTypeProtobuf {
+		md.Set(utils.BodyContentTypeHeader, contentType)
+	}
+	if !options.jsonEncoding && isProtoTarget(responsePointer) {
+		md.Set(utils.BodyAcceptHeader, ContentTypeProtobuf)
+	}
+	tracing.Inject(spanCtx, metadataSetter(md))
+
+	ctx, cancel := context.WithTimeout(spanCtx, options.timeout)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	defer cancel()
+
+	res, err := request(ctx, msg)
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			return FromStatus(st)
+		}
+		return err
+	}
+
+	if responsePointer != nil {
+		return decodeBody(res.msg, responsePointer, res.contentType())
+	}
+
+	return nil
+}
+
+// invokeStream prepares stream context with optional total timeout, trace and metadata,
+// stream returned by open is canceled after idle timeout
+func invokeStream(method string, callerId int, consumer streaming.StreamConsumer, options *invokeOpts,
+	open func(ctx context.Context) (isp.BackendService_RequestStreamClient, error)) (err error) {
+	spanCtx, span := tracing.StartSpan(options.ctx, method, tracing.ClientSpan)
+	defer func() {
+		span.Finish(err)
+	}()
+
+	md := options.md
+	md.Set(utils.ProxyMethodNameHeader, method)
+	md.Set(utils.ApplicationIdHeader, strconv.Itoa(callerId))
+	tracing.Inject(spanCtx, metadataSetter(md))
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if options.timeout > 0 {
+		ctx, cancel = context.WithTimeout(spanCtx, options.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(spanCtx)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	defer cancel()
+
+	streamClient, err := open(ctx)
+	if err != nil {
+		return err
+	}
+
+	if options.idleTimeout <= 0 {
+		return consumer(streamClient, md)
+	}
+	stream := newIdleTimeoutStream(streamClient, options.idleTimeout, cancel)
+	defer stream.stop()
+	return stream.resolveError(consumer(stream, md))
+}
@@ -10,13 +10,26 @@ import (
 
 type InvokeOption func(opts *invokeOpts)
 
+const (
+	defaultInvokeTimeout     = 15 * time.Second
+	defaultStreamIdleTimeout = 15 * time.Second
+)
+
 type invokeOpts struct {
-	md       metadata.MD
-	timeout  time.Duration
-	ctx      context.Context
-	callOpts []grpc.CallOption
+	md          metadata.MD
+	timeout     time.Duration
+	idleTimeout time.Duration
+	ctx         context.Context
+	callOpts    []grpc.CallOption
+
+	retryPolicy *RetryPolicy
+	hedging     *HedgingPolicy
+
+	jsonEncoding     bool
+	protobufEncoding bool
 }
 
+// WithTimeout sets total timeout of call, it is 15 seconds by default for Invoke and unlimited for InvokeStream
 func WithTimeout(timeout time.Duration) InvokeOption {
 	return func(opts *invokeOpts) {
 		opts.timeout = timeout
@@ -41,10 +54,56 @@ func WithContext(ctx context.Context) InvokeOption {
 	}
 }
 
+// WithIdleTimeout sets timeout of InvokeStream without sent or received messages, 15 seconds by default,
+// zero value disables it
+func WithIdleTimeout(timeout time.Duration) InvokeOption {
+	return func(opts *invokeOpts) {
+		opts.idleTimeout = timeout
+	}
+}
+
+// WithRetry overrides retry policy of client and method for call
+func WithRetry(policy RetryPolicy) InvokeOption {
+	return func(opts *invokeOpts) {
+		opts.retryPolicy = &policy
+	}
+}
+
+// WithHedging enables hedging for call instead of retries, it is applied only with idempotent retry policy
+func WithHedging(policy HedgingPolicy) InvokeOption {
+	return func(opts *invokeOpts) {
+		opts.hedging = &policy
+	}
+}
+
 func defaultInvokeOpts() *invokeOpts {
 	return &invokeOpts{
 		md:      metadata.Pairs(),
-		timeout: 15 * time.Second,
+		timeout: defaultInvokeTimeout,
 		ctx:     context.Background(),
 	}
 }
+
+func defaultInvokeStreamOpts() *invokeOpts {
+	return &invokeOpts{
+		md:          metadata.Pairs(),
+		idleTimeout: defaultStreamIdleTimeout,
+		ctx:         context.Background(),
+	}
+}
+
+// WithJsonEncoding disables binary protobuf encoding of request and response,
+// protobuf response is not requested with x-body-accept header
+func WithJsonEncoding() InvokeOption {
+	return func(opts *invokeOpts) {
+		opts.jsonEncoding = true
+	}
+}
+
+// WithProtobufEncoding sends proto message request as binary protobuf,
+// called module must support protobuf bodies, otherwise request is rejected with InvalidArgument
+func WithProtobufEncoding() InvokeOption {
+	return func(opts *invokeOpts) {
+		opts.protobufEncoding = true
+	}
+}
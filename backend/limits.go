@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/rcrowley/go-metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	inFlightMetricPrefix = "grpc.in_flight."
+	queuedMetricPrefix   = "grpc.queued."
+)
+
+// enforces structure.EndpointLimits and counts in-flight requests of single endpoint
+type endpointLimiter struct {
+	method   string
+	limits   structure.EndpointLimits
+	slots    chan struct{} // nil if concurrency is unlimited
+	inFlight int64
+	queued   int64
+}
+
+func newEndpointLimiter(method string, limits structure.EndpointLimits) *endpointLimiter {
+	l := &endpointLimiter{
+		method: method,
+		limits: limits,
+	}
+	if limits.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limits.MaxInFlight)
+	}
+	return l
+}
+
+// returns release function which must be called after request is handled
+func (l *endpointLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if err := l.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	atomic.AddInt64(&l.inFlight, 1)
+	return l.release, nil
+}
+
+func (l *endpointLimiter) wait(ctx context.Context) error {
+	if atomic.AddInt64(&l.queued, 1) > int64(l.limits.MaxQueue) {
+		atomic.AddInt64(&l.queued, -1)
+		return status.Errorf(codes.ResourceExhausted, "Method [%s] reached max in-flight requests: %d", l.method, l.limits.MaxInFlight)
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+
+	var timeout <-chan time.Time
+	if l.limits.QueueTimeout > 0 {
+		timer := time.NewTimer(l.limits.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return status.Errorf(codes.ResourceExhausted, "Method [%s] queue timeout exceeded: %s", l.method, l.limits.QueueTimeout)
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return status.Errorf(codes.DeadlineExceeded, "Method [%s] deadline exceeded in queue", l.method)
+		}
+		return status.Errorf(codes.Canceled, "Method [%s] canceled in queue", l.method)
+	}
+}
+
+func (l *endpointLimiter) release() {
+	atomic.AddInt64(&l.inFlight, -1)
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+func (l *endpointLimiter) InFlight() int64 {
+	return atomic.LoadInt64(&l.inFlight)
+}
+
+func (l *endpointLimiter) Queued() int64 {
+	return atomic.LoadInt64(&l.queued)
+}
+
+func (l *endpointLimiter) registerMetrics(registry metrics.Registry) {
+	// gauges of previous handlers are replaced on handlers update
+	unregisterLimiterMetrics(registry, l.method)
+	_ = registry.Register(inFlightMetricPrefix+l.method, metrics.NewFunctionalGauge(l.InFlight))
+	if l.slots != nil {
+		_ = registry.Register(queuedMetricPrefix+l.method, metrics.NewFunctionalGauge(l.Queued))
+	}
+}
+
+func unregisterLimiterMetrics(registry metrics.Registry, method string) {
+	registry.Unregister(inFlightMetricPrefix + method)
+	registry.Unregister(queuedMetricPrefix + method)
+}
+
+// WithHandleTimeout sets structure.EndpointLimits.Timeout, handler must respect context,
+// otherwise it keeps running after timeout and occupies in-flight slot
+func WithHandleTimeout(timeout time.Duration) EndpointOption {
+	return func(descriptor *structure.EndpointDescriptor) {
+		descriptor.Limits.Timeout = timeout
+	}
+}
+
+// maxQueue requests wait for free slot up to queueTimeout, zero queueTimeout means until request context is done
+func WithMaxInFlight(maxInFlight int, maxQueue int, queueTimeout time.Duration) EndpointOption {
+	return func(descriptor *structure.EndpointDescriptor) {
+		descriptor.Limits.MaxInFlight = maxInFlight
+		descriptor.Limits.MaxQueue = maxQueue
+		descriptor.Limits.QueueTimeout = queueTimeout
+	}
+}
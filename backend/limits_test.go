@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEndpointLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	unblock := make(chan struct{})
+	started := make(chan struct{}, 1)
+	blockingHandler := func(ctx context.Context, req struct{}) (struct{}, error) {
+		started <- struct{}{}
+		<-unblock
+		return struct{}{}, nil
+	}
+	registry := metrics.NewRegistry()
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("limited", blockingHandler, WithMaxInFlight(1, 1, 50*time.Millisecond)),
+		Handle("slow", func(ctx context.Context, req struct{}) (struct{}, error) {
+			time.Sleep(time.Second)
+			return struct{}{}, nil
+		}, WithHandleTimeout(50*time.Millisecond)),
+	}).WithMetricsRegistry(registry)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := requestMethod(service, "limited")
+		errCh <- err
+	}()
+	<-started
+	assert.EqualValues(1, registry.Get("grpc.in_flight.limited").(metrics.Gauge).Value())
+
+	// waits in queue and exceeds queue timeout
+	queuedErrCh := make(chan error, 1)
+	go func() {
+		_, err := requestMethod(service, "limited")
+		queuedErrCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// queue is full
+	_, err := requestMethod(service, "limited")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.Equal(codes.ResourceExhausted, status.Code(<-queuedErrCh))
+
+	close(unblock)
+	assert.NoError(<-errCh)
+	assert.EqualValues(0, registry.Get("grpc.in_flight.limited").(metrics.Gauge).Value())
+
+	_, err = requestMethod(service, "slow")
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	// gauges of removed endpoints and queue gauges of unlimited endpoints are removed on handlers update
+	assert.NotNil(registry.Get("grpc.queued.limited"))
+	_, err = service.UpdateDescriptors([]structure.EndpointDescriptor{
+		Handle("limited", blockingHandler),
+	})
+	assert.NoError(err)
+	assert.NotNil(registry.Get("grpc.in_flight.limited"))
+	assert.Nil(registry.Get("grpc.queued.limited"))
+	assert.Nil(registry.Get("grpc.in_flight.slow"))
+}
+
+func TestEndpointLimits_Reload(t *testing.T) {
+	assert := assert.New(t)
+
+	unblock := make(chan struct{})
+	started := make(chan struct{}, 1)
+	blockingHandler := func(ctx context.Context, req struct{}) (struct{}, error) {
+		started <- struct{}{}
+		<-unblock
+		return struct{}{}, nil
+	}
+	registry := metrics.NewRegistry()
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("limited", blockingHandler, WithMaxInFlight(1, 0, 0)),
+	}).WithMetricsRegistry(registry)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := requestMethod(service, "limited")
+		errCh <- err
+	}()
+	<-started
+
+	// call blocked on previous handlers keeps slot of limiter with unchanged limits
+	drain, err := service.UpdateDescriptors([]structure.EndpointDescriptor{
+		Handle("limited", func(ctx context.Context, req struct{}) (struct{}, error) {
+			return struct{}{}, nil
+		}, WithMaxInFlight(1, 0, 0)),
+	})
+	assert.NoError(err)
+	assert.EqualValues(1, registry.Get("grpc.in_flight.limited").(metrics.Gauge).Value())
+	_, err = requestMethod(service, "limited")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+
+	close(unblock)
+	assert.NoError(<-errCh)
+	assert.NoError(drain(context.Background()))
+	assert.EqualValues(0, registry.Get("grpc.in_flight.limited").(metrics.Gauge).Value())
+	_, err = requestMethod(service, "limited")
+	assert.NoError(err)
+}
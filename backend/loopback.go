@@ -0,0 +1,298 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/streaming"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ GrpcClient = (*LoopbackClient)(nil)
+
+// LoopbackClient is in-memory GrpcClient which calls DefaultService directly without network,
+// requests pass the same metadata, interceptors, validation and post-processors as remote ones.
+// It is useful in unit tests and for modules which embed several services in one binary
+type LoopbackClient struct {
+	conn loopbackConn
+}
+
+func NewLoopbackClient(service *DefaultService) *LoopbackClient {
+	return &LoopbackClient{conn: loopbackConn{service: service}}
+}
+
+// ReceiveAddressList is no-op, service is always available
+func (lc *LoopbackClient) ReceiveAddressList([]structure.AddressConfiguration) bool {
+	return true
+}
+
+func (lc *LoopbackClient) Invoke(method string, callerId int, requestBody, responsePointer interface{}, opts ...InvokeOption) error {
+	options := defaultInvokeOpts()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return invoke(method, callerId, requestBody, responsePointer, options, func(ctx context.Context, msg *isp.Message) (*response, error) {
+		header := metadata.MD{}
+		res, err := lc.conn.Request(ctx, msg, grpc.Header(&header))
+		if err != nil {
+			return nil, err
+		}
+		return &response{msg: res, header: header}, nil
+	})
+}
+
+func (lc *LoopbackClient) InvokeStream(method string, callerId int, consumer streaming.StreamConsumer, opts ...InvokeOption) error {
+	options := defaultInvokeStreamOpts()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return invokeStream(method, callerId, consumer, options, func(ctx context.Context) (isp.BackendService_RequestStreamClient, error) {
+		return lc.conn.RequestStream(ctx)
+	})
+}
+
+func (lc *LoopbackClient) Conn() isp.BackendServiceClient {
+	return lc.conn
+}
+
+func (lc *LoopbackClient) Close() error {
+	return nil
+}
+
+// loopbackConn converts outgoing metadata of client context to incoming metadata of service context,
+// messages are cloned as if they were serialized
+type loopbackConn struct {
+	service *DefaultService
+}
+
+// Request supports grpc.Header call option
+func (c loopbackConn) Request(ctx context.Context, in *isp.Message, opts ...grpc.CallOption) (*isp.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	transport := &loopbackTransportStream{header: metadata.MD{}}
+	serverCtx := grpc.NewContextWithServerTransportStream(incomingContext(ctx), transport)
+	res, err := c.service.Request(serverCtx, cloneMessage(in))
+	for _, opt := range opts {
+		if header, ok := opt.(grpc.HeaderCallOption); ok {
+			*header.HeaderAddr = transport.header.Copy()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cloneMessage(res), nil
+}
+
+func (c loopbackConn) RequestStream(ctx context.Context, _ ...grpc.CallOption) (isp.BackendService_RequestStreamClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	p := &loopbackPipe{
+		clientCtx: ctx,
+		serverCtx: incomingContext(ctx),
+		requests:  make(chan *isp.Message),
+		responses: make(chan *isp.Message),
+		closeSend: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		err := c.service.RequestStream(&loopbackServerStream{p})
+		p.err = err
+		close(p.done)
+	}()
+	return &loopbackClientStream{p}, nil
+}
+
+// loopbackTransportStream collects header set by service with grpc.SetHeader
+type loopbackTransportStream struct {
+	lock   sync.Mutex
+	header metadata.MD
+}
+
+func (s *loopbackTransportStream) Method() string {
+	return ""
+}
+
+func (s *loopbackTransportStream) SetHeader(md metadata.MD) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *loopbackTransportStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *loopbackTransportStream) SetTrailer(metadata.MD) error {
+	return nil
+}
+
+func incomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(ctx, md.Copy())
+}
+
+func cloneMessage(msg *isp.Message) *isp.Message {
+	if msg == nil {
+		return nil
+	}
+	return proto.Clone(msg).(*isp.Message)
+}
+
+// loopbackPipe connects client and server sides of stream with unbuffered channels,
+// so message is delivered when Send returns and all responses are received before handler returns
+type loopbackPipe struct {
+	clientCtx context.Context
+	serverCtx context.Context
+	requests  chan *isp.Message
+	responses chan *isp.Message
+	closeSend chan struct{}
+	closeOnce sync.Once
+	// handler error, it is set before done is closed
+	err  error
+	done chan struct{}
+}
+
+type loopbackClientStream struct {
+	*loopbackPipe
+}
+
+func (s *loopbackClientStream) Send(msg *isp.Message) error {
+	select {
+	case <-s.closeSend:
+		return status.Error(codes.Internal, "send on closed stream")
+	default:
+	}
+	select {
+	case s.requests <- cloneMessage(msg):
+		return nil
+	case <-s.done:
+		return io.EOF
+	case <-s.clientCtx.Done():
+		return status.FromContextError(s.clientCtx.Err()).Err()
+	}
+}
+
+func (s *loopbackClientStream) Recv() (*isp.Message, error) {
+	select {
+	case msg := <-s.responses:
+		return msg, nil
+	case <-s.done:
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	case <-s.clientCtx.Done():
+		return nil, status.FromContextError(s.clientCtx.Err()).Err()
+	}
+}
+
+func (s *loopbackClientStream) CloseSend() error {
+	s.closeOnce.Do(func() {
+		close(s.closeSend)
+	})
+	return nil
+}
+
+func (s *loopbackClientStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *loopbackClientStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *loopbackClientStream) Context() context.Context {
+	return s.clientCtx
+}
+
+func (s *loopbackClientStream) SendMsg(m interface{}) error {
+	msg, ok := m.(*isp.Message)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return s.Send(msg)
+}
+
+func (s *loopbackClientStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(*isp.Message)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	res, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(msg, res)
+	return nil
+}
+
+type loopbackServerStream struct {
+	*loopbackPipe
+}
+
+func (s *loopbackServerStream) Send(msg *isp.Message) error {
+	select {
+	case s.responses <- cloneMessage(msg):
+		return nil
+	case <-s.serverCtx.Done():
+		return status.FromContextError(s.serverCtx.Err()).Err()
+	}
+}
+
+func (s *loopbackServerStream) Recv() (*isp.Message, error) {
+	select {
+	case msg := <-s.requests:
+		return msg, nil
+	case <-s.closeSend:
+		return nil, io.EOF
+	case <-s.serverCtx.Done():
+		return nil, status.FromContextError(s.serverCtx.Err()).Err()
+	}
+}
+
+func (s *loopbackServerStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *loopbackServerStream) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *loopbackServerStream) SetTrailer(metadata.MD) {
+}
+
+func (s *loopbackServerStream) Context() context.Context {
+	return s.serverCtx
+}
+
+func (s *loopbackServerStream) SendMsg(m interface{}) error {
+	msg, ok := m.(*isp.Message)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	return s.Send(msg)
+}
+
+func (s *loopbackServerStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(*isp.Message)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+	res, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(msg, res)
+	return nil
+}
@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/integration-system/isp-lib/v2/isp"
+	"github.com/integration-system/isp-lib/v2/streaming"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoopbackClient_Invoke(t *testing.T) {
+	assert := assert.New(t)
+
+	intercepted, processed := 0, 0
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		Handle("sum", func(ctx context.Context, req sumRequest) (*sumResponse, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			assert.Equal([]string{"value"}, md.Get("x-test"))
+			return &sumResponse{Sum: req.A + req.B}, nil
+		}),
+	}).Use(func(ctx RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
+		intercepted++
+		return proceed()
+	}).WithPostProcessors(func(ctx RequestCtx) {
+		processed++
+	})
+	cli := NewLoopbackClient(service)
+
+	sum := NewMethod[sumRequest, *sumResponse](cli, "sum", WithMetadata(metadata.Pairs("x-test", "value")))
+	resp, err := sum.Invoke(context.Background(), 1, sumRequest{A: 1, B: 2})
+	assert.NoError(err)
+	assert.Equal(3, resp.Sum)
+
+	// validation error
+	_, err = sum.Invoke(context.Background(), 1, sumRequest{B: 2})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	err = cli.Invoke("unknown", 1, nil, nil)
+	assert.Equal(codes.Unimplemented, status.Code(err))
+	assert.Equal(1, intercepted)
+	assert.Equal(3, processed)
+}
+
+func TestLoopbackClient_InvokeStream(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewDefaultService([]structure.EndpointDescriptor{
+		{
+			Path: "echo",
+			Handler: func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+				for {
+					msg, err := stream.Recv()
+					if err == io.EOF {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					if msg.GetBytesBody() == nil {
+						return status.Error(codes.InvalidArgument, "bytes expected")
+					}
+					if err := stream.Send(msg); err != nil {
+						return err
+					}
+				}
+			},
+		},
+	})
+	cli := NewLoopbackClient(service)
+
+	received := make([]string, 0)
+	err := cli.InvokeStream("echo", 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		for _, s := range []string{"a", "b", "c"} {
+			if err := stream.Send(&isp.Message{Body: &isp.Message_BytesBody{BytesBody: []byte(s)}}); err != nil {
+				return err
+			}
+			msg, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			received = append(received, string(msg.GetBytesBody()))
+		}
+		if err := stream.(interface{ CloseSend() error }).CloseSend(); err != nil {
+			return err
+		}
+		_, err := stream.Recv()
+		assert.Equal(io.EOF, err)
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b", "c"}, received)
+
+	// handler error is received by client
+	err = cli.InvokeStream("echo", 1, func(stream streaming.DuplexMessageStream, md metadata.MD) error {
+		if err := stream.Send(emptyBody); err != nil {
+			return err
+		}
+		_, err := stream.Recv()
+		return err
+	})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrStreamHandler is returned by HandlerTypes for stream handlers
+var ErrStreamHandler = errors.New("stream handler has no request and response types")
+
+// Method is typed stub of other module method, Req and Resp must match types of remote handler. Example:
+// var getObject = backend.NewMethod[GetRequest, *Object](client, "module/object/get")
+type Method[Req, Resp any] struct {
+	client GrpcClient
+	path   string
+	opts   []InvokeOption
+}
+
+// NewMethod creates typed stub of method, opts are applied to each call before call options
+func NewMethod[Req, Resp any](client GrpcClient, path string, opts ...InvokeOption) Method[Req, Resp] {
+	return Method[Req, Resp]{
+		client: client,
+		path:   path,
+		opts:   opts,
+	}
+}
+
+func (m Method[Req, Resp]) Path() string {
+	return m.path
+}
+
+// Invoke calls method with ctx, ctx is propagated as with WithContext option
+func (m Method[Req, Resp]) Invoke(ctx context.Context, callerId int, req Req, opts ...InvokeOption) (Resp, error) {
+	var resp Resp
+	options := make([]InvokeOption, 0, len(m.opts)+len(opts)+1)
+	options = append(options, WithContext(ctx))
+	options = append(options, m.opts...)
+	options = append(options, opts...)
+	err := m.client.Invoke(m.path, callerId, req, &resp, options...)
+	return resp, err
+}
+
+// HandlerTypes returns request and response types of endpoint handler, types are nil if handler has no data param
+// or result, returns error for stream handlers and invalid functions
+func HandlerTypes(handler interface{}) (req reflect.Type, resp reflect.Type, err error) {
+	if h, ok := handler.(typedHandler); ok {
+		req, resp = h.types()
+		return req, resp, nil
+	}
+	if getStreamConsumer(handler) != nil {
+		return nil, nil, ErrStreamHandler
+	}
+	if handler == nil {
+		return nil, nil, errors.New("nil handler")
+	}
+	value := reflect.ValueOf(handler)
+	f, err := getFunction(value.Type(), value)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i < value.Type().NumOut(); i++ {
+		out := value.Type().Out(i)
+		if out != errorInterface {
+			resp = out
+			break
+		}
+	}
+	return f.dataParamType, resp, nil
+}
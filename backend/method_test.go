@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	addr, srv := startHandlerServer(retryMethodPath, func(req sumRequest) (*sumResponse, error) {
+		return &sumResponse{Sum: req.A + req.B}, nil
+	})
+	defer srv.Stop()
+	cli := newTestClient([]structure.AddressConfiguration{addr})
+	defer cli.Close()
+
+	sum := NewMethod[sumRequest, *sumResponse](cli, retryMethodPath)
+	assert.Equal(retryMethodPath, sum.Path())
+	resp, err := sum.Invoke(context.Background(), 1, sumRequest{A: 1, B: 2})
+	assert.NoError(err)
+	assert.Equal(3, resp.Sum)
+}
+
+func TestHandlerTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	req, resp, err := HandlerTypes(func(ctx context.Context, req sumRequest) (*sumResponse, error) { return nil, nil })
+	assert.NoError(err)
+	assert.Equal("sumRequest", req.Name())
+	assert.Equal("*backend.sumResponse", resp.String())
+
+	descriptor := Handle("sum", func(ctx context.Context, req *sumRequest) ([]sumResponse, error) { return nil, nil })
+	req, resp, err = HandlerTypes(descriptor.Handler)
+	assert.NoError(err)
+	assert.Equal("*backend.sumRequest", req.String())
+	assert.Equal("[]backend.sumResponse", resp.String())
+
+	req, resp, err = HandlerTypes(func() error { return nil })
+	assert.NoError(err)
+	assert.Nil(req)
+	assert.Nil(resp)
+}
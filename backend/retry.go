@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy describes which failed calls are retried and how long to wait between attempts
+type RetryPolicy struct {
+	// retried status codes
+	Codes []codes.Code
+	// total attempts count including the first one, values less than 2 disable retries
+	MaxAttempts int
+	// zero InitialBackoff means retry without delay
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// randomization factor of backoff in range [0, 1]
+	Jitter float64
+	// not idempotent calls are retried only with Unavailable code, when request is most likely not handled,
+	// hedging is applied only to idempotent calls
+	Idempotent bool
+}
+
+// HedgingPolicy sends additional copies of idempotent request if there is no response in Delay,
+// the first successful or not retryable response wins and other attempts are canceled
+type HedgingPolicy struct {
+	// total attempts count including the first one, RetryPolicy.MaxAttempts is ignored for hedged call
+	MaxAttempts int
+	Delay       time.Duration
+}
+
+// DefaultRetryPolicy retries Unavailable calls twice without delay
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Codes:       []codes.Code{codes.Unavailable},
+		MaxAttempts: 3,
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	if !p.Idempotent && code != codes.Unavailable {
+		return false
+	}
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) backOff(ctx context.Context) backoff.BackOff {
+	var b backoff.BackOff = new(backoff.ZeroBackOff)
+	if p.InitialBackoff > 0 {
+		exp := backoff.NewExponentialBackOff()
+		exp.InitialInterval = p.InitialBackoff
+		exp.RandomizationFactor = p.Jitter
+		exp.MaxElapsedTime = 0
+		if p.Multiplier > 0 {
+			exp.Multiplier = p.Multiplier
+		}
+		if p.MaxBackoff > 0 {
+			exp.MaxInterval = p.MaxBackoff
+		}
+		exp.Reset()
+		b = exp
+	}
+	retries := p.MaxAttempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+	return backoff.WithContext(backoff.WithMaxRetries(b, uint64(retries)), ctx)
+}
+
+// do calls f until it succeeds, returns not retryable error or attempts are exhausted
+func (p RetryPolicy) do(ctx context.Context, f func() error) error {
+	var responseErr error
+	err := backoff.Retry(func() error {
+		responseErr = f()
+		if p.retryable(responseErr) {
+			return responseErr
+		}
+		return nil
+	}, p.backOff(ctx))
+
+	if responseErr != nil {
+		return responseErr
+	}
+	return err
+}
+
+type hedgingResult struct {
+	res *response
+	err error
+}
+
+// hedge sends attempts with policy.Delay interval or immediately after retryable failure
+func (p HedgingPolicy) hedge(ctx context.Context, retryable func(error) bool, attempt func(ctx context.Context) (*response, error)) (*response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgingResult, p.MaxAttempts)
+	sent, received := 0, 0
+	send := func() {
+		sent++
+		go func() {
+			res, err := attempt(ctx)
+			results <- hedgingResult{res: res, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(p.Delay)
+	defer timer.Stop()
+	send()
+	var last hedgingResult
+	for {
+		select {
+		case last = <-results:
+			received++
+			if last.err == nil || !retryable(last.err) {
+				return last.res, last.err
+			}
+			if sent < p.MaxAttempts {
+				send()
+			} else if received == sent {
+				return last.res, last.err
+			}
+		case <-timer.C:
+			if sent < p.MaxAttempts {
+				send()
+				timer.Reset(p.Delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const retryMethodPath = "retry/path"
+
+func TestRxGrpcClient_RetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := int32(0)
+	addr, srv := startHandlerServer(retryMethodPath, func() (string, error) {
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			return "", status.Error(codes.Internal, "internal")
+		}
+		return "ok", nil
+	})
+	defer srv.Stop()
+	cli := newTestClient([]structure.AddressConfiguration{addr})
+	defer cli.Close()
+
+	policy := RetryPolicy{
+		Codes:          []codes.Code{codes.Internal},
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Jitter:         0.2,
+		Idempotent:     true,
+	}
+	var answer string
+	err := cli.Invoke(retryMethodPath, 1, nil, &answer, WithRetry(policy))
+	assert.NoError(err)
+	assert.Equal("ok", answer)
+	assert.EqualValues(3, atomic.LoadInt32(&calls))
+
+	// not idempotent call is not retried with Internal code
+	policy.Idempotent = false
+	err = cli.Invoke(retryMethodPath, 1, nil, &answer, WithRetry(policy))
+	assert.Equal(codes.Internal, status.Code(err))
+	assert.EqualValues(4, atomic.LoadInt32(&calls))
+}
+
+func TestRxGrpcClient_Hedging(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := int32(0)
+	addr, srv := startHandlerServer(retryMethodPath, func() (string, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(time.Second)
+			return "slow", nil
+		}
+		return "fast", nil
+	})
+	defer srv.Stop()
+	cli := newTestClient([]structure.AddressConfiguration{addr},
+		WithMethodRetryPolicy(retryMethodPath, RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 1, Idempotent: true}),
+		WithMethodHedging(retryMethodPath, HedgingPolicy{MaxAttempts: 2, Delay: 20 * time.Millisecond}),
+	)
+	defer cli.Close()
+
+	start := time.Now()
+	var answer string
+	err := cli.Invoke(retryMethodPath, 1, nil, &answer)
+	assert.NoError(err)
+	assert.Equal("fast", answer)
+	assert.Less(int64(time.Since(start)), int64(500*time.Millisecond))
+}
+
+func TestRxGrpcClient_HedgingWithoutRetries(t *testing.T) {
+	calls := int32(0)
+	addr, srv := startHandlerServer(retryMethodPath, func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", status.Error(codes.Unavailable, "unavailable")
+	})
+	defer srv.Stop()
+	cli := newTestClient([]structure.AddressConfiguration{addr},
+		WithMethodRetryPolicy(retryMethodPath, RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 3, Idempotent: true}),
+		WithMethodHedging(retryMethodPath, HedgingPolicy{MaxAttempts: 2, Delay: 10 * time.Millisecond}),
+	)
+	defer cli.Close()
+
+	var answer string
+	err := cli.Invoke(retryMethodPath, 1, nil, &answer)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestRxGrpcClient_CircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	failing, failingSrv := startHandlerServer(retryMethodPath, func() (string, error) {
+		return "", status.Error(codes.Unavailable, "unavailable")
+	})
+	defer failingSrv.Stop()
+	healthy, healthySrv := startHandlerServer(retryMethodPath, func() (string, error) {
+		return "ok", nil
+	})
+	defer healthySrv.Stop()
+
+	cli := NewRxGrpcClient(
+		WithDialOptions(grpc.WithInsecure()),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 200 * time.Millisecond}),
+	)
+	defer cli.Close()
+	// circuit state is kept by configured address, not by resolved one
+	failing.IP = "localhost"
+	cli.ReceiveAddressList([]structure.AddressConfiguration{failing, healthy})
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		var answer string
+		_ = cli.Invoke(retryMethodPath, 1, nil, &answer)
+	}
+	assert.True(cli.breaker.isEjected(failing.GetAddress()))
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		var answer string
+		err := cli.Invoke(retryMethodPath, 1, nil, &answer)
+		assert.NoError(err)
+	}
+
+	// address returns to balancing after timeout
+	time.Sleep(200 * time.Millisecond)
+	assert.False(cli.breaker.isEjected(failing.GetAddress()))
+}
+
+func TestCircuitBreaker_IgnoresCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	cb := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}, func() {})
+	addr := resolverAddr("localhost:9999")
+	cb.report(addr, status.Error(codes.Unavailable, "unavailable"))
+	// canceled call doesn't reset failures
+	cb.report(addr, status.Error(codes.Canceled, "canceled"))
+	cb.report(nil, nil)
+	cb.report(addr, status.Error(codes.Unavailable, "unavailable"))
+	assert.True(cb.isEjected(addr.String()))
+}
+
+func TestRxGrpcClient_CircuitBreakerWithDialer(t *testing.T) {
+	assert := assert.New(t)
+
+	failing, failingSrv := startHandlerServer(retryMethodPath, func() (string, error) {
+		return "", status.Error(codes.Unavailable, "unavailable")
+	})
+	defer failingSrv.Stop()
+
+	dials := int32(0)
+	cli := NewRxGrpcClient(
+		WithDialOptions(grpc.WithInsecure()),
+		WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			return new(net.Dialer).DialContext(ctx, "tcp", address)
+		}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute}),
+	)
+	defer cli.Close()
+	failing.IP = "localhost"
+	cli.ReceiveAddressList([]structure.AddressConfiguration{failing})
+
+	var answer string
+	_ = cli.Invoke(retryMethodPath, 1, nil, &answer, WithTimeout(time.Second))
+	assert.Positive(atomic.LoadInt32(&dials))
+	// breaker wraps dialer, so address is matched by configured hostname
+	assert.True(cli.breaker.isEjected(failing.GetAddress()))
+}
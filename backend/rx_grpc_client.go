@@ -3,17 +3,16 @@ package backend
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"time"
+	"net"
+	"sync"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/integration-system/isp-lib/v2/isp"
-	"github.com/integration-system/isp-lib/v2/utils"
-	"google.golang.org/grpc/codes"
+	"github.com/integration-system/isp-lib/v2/tlsconfig"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
-	"google.golang.org/grpc/status"
 
 	"github.com/integration-system/isp-lib/v2/streaming"
 	"github.com/integration-system/isp-lib/v2/structure"
@@ -29,7 +28,7 @@ const (
 type GrpcClient interface {
 	ReceiveAddressList([]structure.AddressConfiguration) bool
 	Invoke(method string, callerId int, requestBody, responsePointer interface{}, opts ...InvokeOption) error
-	InvokeStream(method string, callerId int, consumer streaming.StreamConsumer) error
+	InvokeStream(method string, callerId int, consumer streaming.StreamConsumer, opts ...InvokeOption) error
 	Conn() isp.BackendServiceClient
 	Close() error
 }
@@ -38,11 +37,24 @@ var _ GrpcClient = (*RxGrpcClient)(nil)
 
 type RxGrpcClient struct {
 	options         []grpc.DialOption
+	tlsCreds        credentials.TransportCredentials
+	tlsCfg          *structure.TLSConfiguration
+	dialer          func(ctx context.Context, address string) (net.Conn, error)
 	connsPerAddress int
+	retryPolicy     RetryPolicy
+	methodRetry     map[string]RetryPolicy
+	methodHedging   map[string]HedgingPolicy
+	breakerCfg      *CircuitBreakerConfig
 
 	conn     *grpc.ClientConn
 	ispConn  isp.BackendServiceClient
 	resolver *manual.Resolver
+	breaker  *circuitBreaker
+	// loader of WithClientTLSConfig, it is closed on Close
+	tlsLoader *tlsconfig.Loader
+
+	addrLock sync.Mutex
+	addrList []structure.AddressConfiguration
 }
 
 func (rc *RxGrpcClient) ReceiveAddressList(list []structure.AddressConfiguration) bool {
@@ -50,6 +62,33 @@ func (rc *RxGrpcClient) ReceiveAddressList(list []structure.AddressConfiguration
 		return true
 	}
 
+	rc.addrLock.Lock()
+	rc.addrList = list
+	rc.addrLock.Unlock()
+	rc.updateResolverState()
+
+	return true
+}
+
+// updates resolver with received addresses except ejected by circuit breaker,
+// all addresses are used if all of them are ejected
+func (rc *RxGrpcClient) updateResolverState() {
+	rc.addrLock.Lock()
+	defer rc.addrLock.Unlock()
+
+	list := make([]structure.AddressConfiguration, 0, len(rc.addrList))
+	for _, addr := range rc.addrList {
+		if !rc.breaker.isEjected(addr.GetAddress()) {
+			list = append(list, addr)
+		}
+	}
+	if len(list) == 0 {
+		list = rc.addrList
+	}
+	if len(list) == 0 {
+		return
+	}
+
 	resolvedAddrs := make([]resolver.Address, 0, len(list)*rc.connsPerAddress)
 	for j := 1; j < rc.connsPerAddress+1; j++ {
 		for i := 0; i < len(list); i++ {
@@ -61,65 +100,77 @@ func (rc *RxGrpcClient) ReceiveAddressList(list []structure.AddressConfiguration
 		}
 	}
 	rc.resolver.UpdateState(resolver.State{Addresses: resolvedAddrs})
-
-	return true
 }
 
+// Invoke returns *Error for grpc status errors, its details and ErrorCode are restored from status
 func (rc *RxGrpcClient) Invoke(method string, callerId int, requestBody, responsePointer interface{}, opts ...InvokeOption) error {
 	options := defaultInvokeOpts()
 	for _, opt := range opts {
 		opt(options)
 	}
+	return invoke(method, callerId, requestBody, responsePointer, options, func(ctx context.Context, msg *isp.Message) (*response, error) {
+		return rc.request(ctx, method, msg, options)
+	})
+}
 
-	md := options.md
-	md.Set(utils.ProxyMethodNameHeader, method)
-	md.Set(utils.ApplicationIdHeader, strconv.Itoa(callerId))
-
-	ctx, cancel := context.WithTimeout(options.ctx, options.timeout)
-	ctx = metadata.NewOutgoingContext(ctx, md)
-	defer cancel()
-
-	msg, err := toBytes(requestBody)
-	if err != nil {
-		return err
+// request sends msg with retry or hedging policy of invoke options or method
+func (rc *RxGrpcClient) request(ctx context.Context, method string, msg *isp.Message, options *invokeOpts) (*response, error) {
+	retryPolicy := rc.getRetryPolicy(method, options)
+	hedging, hedged := rc.methodHedging[method]
+	if options.hedging != nil {
+		hedging, hedged = *options.hedging, true
+	}
+	hedged = hedged && retryPolicy.Idempotent && hedging.MaxAttempts > 1
+
+	attempt := func(ctx context.Context) (*response, error) {
+		p := new(peer.Peer)
+		header := metadata.MD{}
+		callOpts := append(append([]grpc.CallOption{}, options.callOpts...), grpc.Peer(p), grpc.Header(&header))
+		res, err := rc.ispConn.Request(ctx, msg, callOpts...)
+		rc.breaker.report(p.Addr, err)
+		if err != nil {
+			return nil, err
+		}
+		return &response{msg: res, header: header}, nil
 	}
 
-	var res *isp.Message
-	err = retryUnavailable(func() (err error) {
-		res, err = rc.ispConn.Request(ctx, msg, options.callOpts...)
+	// hedging replaces retries as in grpc, so call sends at most HedgingPolicy.MaxAttempts requests
+	if hedged {
+		return hedging.hedge(ctx, retryPolicy.retryable, attempt)
+	}
+	var res *response
+	err := retryPolicy.do(ctx, func() (err error) {
+		res, err = attempt(ctx)
 		return
 	})
-	if err != nil {
-		return err
-	}
+	return res, err
+}
 
-	if responsePointer != nil {
-		return readBody(res, responsePointer)
+func (rc *RxGrpcClient) getRetryPolicy(method string, options *invokeOpts) RetryPolicy {
+	if options.retryPolicy != nil {
+		return *options.retryPolicy
 	}
-
-	return nil
+	if p, ok := rc.methodRetry[method]; ok {
+		return p
+	}
+	return rc.retryPolicy
 }
 
-func (rc *RxGrpcClient) InvokeStream(method string, callerId int, consumer streaming.StreamConsumer) error {
-	md := metadata.Pairs(
-		utils.ProxyMethodNameHeader, method,
-		utils.ApplicationIdHeader, strconv.Itoa(callerId),
-	)
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
-	ctx = metadata.NewOutgoingContext(ctx, md)
-	defer cancel()
-
-	var streamClient isp.BackendService_RequestStreamClient
-	err := retryUnavailable(func() (err error) {
-		streamClient, err = rc.ispConn.RequestStream(ctx)
+// InvokeStream opens stream with invoke options, opening is retried with retry policy.
+// Stream is not limited by total timeout unless WithTimeout is passed and is canceled
+// if there are no sent or received messages during idle timeout, see WithIdleTimeout
+func (rc *RxGrpcClient) InvokeStream(method string, callerId int, consumer streaming.StreamConsumer, opts ...InvokeOption) error {
+	options := defaultInvokeStreamOpts()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return invokeStream(method, callerId, consumer, options, func(ctx context.Context) (stream isp.BackendService_RequestStreamClient, err error) {
+		err = rc.getRetryPolicy(method, options).do(ctx, func() (err error) {
+			stream, err = rc.ispConn.RequestStream(ctx, options.callOpts...)
+			return
+		})
 		return
 	})
-	if err != nil {
-		return err
-	}
-
-	return consumer(streamClient, md)
 }
 
 func (rc *RxGrpcClient) Conn() isp.BackendServiceClient {
@@ -127,41 +178,54 @@ func (rc *RxGrpcClient) Conn() isp.BackendServiceClient {
 }
 
 func (rc *RxGrpcClient) Close() error {
-	return rc.conn.Close()
-}
-
-func retryUnavailable(f func() error) error {
-	bf := backoff.WithMaxRetries(new(backoff.ZeroBackOff), 2)
-	var responseErr error
-	err := backoff.Retry(func() error {
-		err := f()
-		switch status.Code(err) {
-		case codes.Unavailable:
-			return err
-		default:
-			responseErr = err
-			return nil
-		}
-	}, bf)
-
-	if responseErr != nil {
-		return responseErr
+	err := rc.conn.Close()
+	if rc.tlsLoader != nil {
+		_ = rc.tlsLoader.Close()
 	}
 	return err
 }
 
-// NewRxGrpcClient is incompatible with grpc.WithBlock() option
+// NewRxGrpcClient is incompatible with grpc.WithBlock() option,
+// it panics if files of WithClientTLSConfig can't be read
 func NewRxGrpcClient(opts ...RxOption) *RxGrpcClient {
-	client := &RxGrpcClient{}
+	client := &RxGrpcClient{
+		retryPolicy:   DefaultRetryPolicy(),
+		methodRetry:   make(map[string]RetryPolicy),
+		methodHedging: make(map[string]HedgingPolicy),
+	}
 	for _, o := range opts {
 		o(client)
 	}
 	if client.connsPerAddress <= 0 {
 		client.connsPerAddress = defaultConnsPerAddress
 	}
+	if client.breakerCfg != nil {
+		client.breaker = newCircuitBreaker(*client.breakerCfg, client.updateResolverState)
+	}
+	if client.tlsCfg != nil && client.tlsCreds == nil {
+		loader, err := tlsconfig.NewLoader(*client.tlsCfg)
+		if err != nil {
+			panic(err)
+		}
+		client.tlsLoader = loader
+		client.tlsCreds = loader.ClientCredentials()
+	}
 
 	client.resolver = manual.NewBuilderWithScheme(resolverScheme)
-	dialOpts := append(client.options,
+	dialOpts := make([]grpc.DialOption, 0)
+	dialer := client.dialer
+	if client.breaker != nil {
+		dialer = breakerDialer(dialer)
+	}
+	if dialer != nil {
+		// dialer of WithDialOptions overrides it
+		dialOpts = append(dialOpts, grpc.WithContextDialer(dialer))
+	}
+	dialOpts = append(dialOpts, client.options...)
+	if client.tlsCreds != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(client.tlsCreds))
+	}
+	dialOpts = append(dialOpts,
 		grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy": "round_robin"}`),
 		grpc.WithResolvers(client.resolver),
 	)
@@ -186,8 +250,62 @@ func WithDialOptions(opts ...grpc.DialOption) RxOption {
 	}
 }
 
+// WithClientTLS connects to backends with TLS, it overrides transport credentials of WithDialOptions.
+// Server certificate is verified against address host if server name is not configured
+func WithClientTLS(loader *tlsconfig.Loader) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.tlsCreds = loader.ClientCredentials()
+	}
+}
+
+// WithClientTLSConfig is like WithClientTLS, but client owns loader of configuration and closes it on Close.
+// WithClientTLS has priority over it
+func WithClientTLSConfig(cfg structure.TLSConfiguration) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.tlsCfg = &cfg
+	}
+}
+
 func WithConnectionsPerAddress(factor int) RxOption {
 	return func(rc *RxGrpcClient) {
 		rc.connsPerAddress = factor
 	}
 }
+
+// WithRetryPolicy sets default retry policy of client calls
+func WithRetryPolicy(policy RetryPolicy) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.retryPolicy = policy
+	}
+}
+
+func WithMethodRetryPolicy(method string, policy RetryPolicy) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.methodRetry[method] = policy
+	}
+}
+
+// WithMethodHedging enables hedging for method, it is applied only with idempotent retry policy,
+// retry policy attempts are not used then, failed attempt is retryable according to retry policy codes
+func WithMethodHedging(method string, policy HedgingPolicy) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.methodHedging[method] = policy
+	}
+}
+
+// WithContextDialer sets dialer of backend connections, unlike grpc.WithContextDialer of WithDialOptions
+// it is compatible with WithCircuitBreaker
+func WithContextDialer(dialer func(ctx context.Context, address string) (net.Conn, error)) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.dialer = dialer
+	}
+}
+
+// WithCircuitBreaker ejects addresses which fail cfg.FailureThreshold times in a row from balancing for cfg.OpenTimeout.
+// Breaker wraps dialer to match connections with resolved addresses, so grpc.WithContextDialer passed with
+// WithDialOptions overrides it and addresses with hostnames are never ejected then, use WithContextDialer instead
+func WithCircuitBreaker(cfg CircuitBreakerConfig) RxOption {
+	return func(rc *RxGrpcClient) {
+		rc.breakerCfg = &cfg
+	}
+}
@@ -3,9 +3,7 @@
 package backend
 
 import (
-	"net"
 	"strconv"
-	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -139,20 +137,7 @@ func setupServers(count int) ([]structure.AddressConfiguration, []*GrpcServer) {
 				},
 			},
 		}
-		service := NewDefaultService(descriptors)
-
-		l, err := net.Listen("tcp", "127.0.0.1:0")
-		if err != nil {
-			panic(err)
-		}
-
-		port := strings.Split(l.Addr().String(), ":")[1]
-		addr := structure.AddressConfiguration{IP: "127.0.0.1", Port: port}
-		addrs[i] = addr
-
-		srv := newBackendGrpcServer(l, service)
-		go srv.Start()
-		servers[i] = srv
+		addrs[i], servers[i] = startTestServer(NewDefaultService(descriptors))
 	}
 
 	return addrs, servers
@@ -1,90 +1,298 @@
 package backend
 
 import (
+	"context"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/integration-system/isp-lib/v2/isp"
 	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/tlsconfig"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 )
 
+// DefaultServerName is name of server started by package level StartBackendGrpcServer
+const DefaultServerName = "default"
+
 var (
-	server *GrpcServer
-	lock   = sync.Mutex{}
+	server           *GrpcServer
+	routesDeclarator RoutesDeclarator
+	lock             = sync.Mutex{}
 )
 
-func newBackendGrpcServer(listener net.Listener, service *DefaultService, opt ...grpc.ServerOption) *GrpcServer {
-	grpcServer := grpc.NewServer(opt...)
-	isp.RegisterBackendServiceServer(grpcServer, service)
-	srv := &GrpcServer{
-		Server:   grpcServer,
-		service:  service,
-		listener: listener,
+// implemented by bootstrap.RoutesDeclarator, declares current module endpoints to config service
+type RoutesDeclarator interface {
+	DeclareRoutes()
+}
+
+// ListenRetryPolicy describes how server retries to open busy port on Start
+type ListenRetryPolicy struct {
+	// total attempts count, zero means retry until Start ctx is done
+	MaxAttempts int
+	Interval    time.Duration
+}
+
+func DefaultListenRetryPolicy() ListenRetryPolicy {
+	return ListenRetryPolicy{Interval: 3 * time.Second}
+}
+
+type ServerOption func(s *GrpcServer)
+
+func WithGrpcServerOptions(opts ...grpc.ServerOption) ServerOption {
+	return func(s *GrpcServer) {
+		s.grpcOpts = append(s.grpcOpts, opts...)
+	}
+}
+
+// WithListener sets opened listener, address passed to NewGrpcServer is not listened then
+func WithListener(ln net.Listener) ServerOption {
+	return func(s *GrpcServer) {
+		s.listener = ln
 	}
+}
+
+func WithListenRetryPolicy(policy ListenRetryPolicy) ServerOption {
+	return func(s *GrpcServer) {
+		s.listenRetry = policy
+	}
+}
 
-	return srv
+// WithServerTLS serves TLS connections with certificates of loader, client certificates are verified
+// according to loader configuration
+func WithServerTLS(loader *tlsconfig.Loader) ServerOption {
+	return func(s *GrpcServer) {
+		s.grpcOpts = append(s.grpcOpts, grpc.Creds(loader.ServerCredentials()))
+	}
 }
 
+func WithServerRoutesDeclarator(declarator RoutesDeclarator) ServerOption {
+	return func(s *GrpcServer) {
+		s.declarator = declarator
+	}
+}
+
+// GrpcServer serves DefaultService on single address, module may start several servers with different names,
+// e.g. for inner and outer ports
 type GrpcServer struct {
 	*grpc.Server
-	listener net.Listener
-	service  *DefaultService
+	name        string
+	addr        structure.AddressConfiguration
+	grpcOpts    []grpc.ServerOption
+	listenRetry ListenRetryPolicy
+	service     *DefaultService
+
+	// loader of address TLS configuration, it is closed on Shutdown
+	tls    *tlsconfig.Loader
+	tlsErr error
+
+	startLock sync.Mutex
+	listener  net.Listener
+	started   bool
+	done      chan struct{}
+	serveErr  error
+
+	declaratorLock sync.Mutex
+	declarator     RoutesDeclarator
+}
+
+// NewGrpcServer creates server of service, if addr has TLS configuration, server accepts only TLS connections,
+// WithServerTLS overrides it. Error of reading TLS files is returned by Start
+func NewGrpcServer(name string, addr structure.AddressConfiguration, service *DefaultService, opts ...ServerOption) *GrpcServer {
+	s := &GrpcServer{
+		name:        name,
+		addr:        addr,
+		listenRetry: DefaultListenRetryPolicy(),
+		service:     service,
+		done:        make(chan struct{}),
+	}
+	if addr.TLS != nil {
+		s.tls, s.tlsErr = tlsconfig.NewLoader(*addr.TLS)
+		if s.tlsErr == nil {
+			s.grpcOpts = append(s.grpcOpts, grpc.Creds(s.tls.ServerCredentials()))
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = grpc.NewServer(s.grpcOpts...)
+	isp.RegisterBackendServiceServer(s.Server, service)
+	return s
+}
+
+func (s *GrpcServer) Name() string {
+	return s.name
+}
+
+// Addr returns listened address, it is nil before Start
+func (s *GrpcServer) Addr() net.Addr {
+	s.startLock.Lock()
+	defer s.startLock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Start opens port according to listen retry policy and serves requests in background,
+// ctx limits only waiting of busy port
+func (s *GrpcServer) Start(ctx context.Context) error {
+	s.startLock.Lock()
+	defer s.startLock.Unlock()
+
+	if s.started {
+		return errors.Errorf("grpc server %s has already started", s.name)
+	}
+	if s.tlsErr != nil {
+		return errors.WithMessagef(s.tlsErr, "grpc server %s", s.name)
+	}
+	if s.listener == nil {
+		ln, err := s.listen(ctx)
+		if err != nil {
+			return err
+		}
+		s.listener = ln
+	}
+	s.started = true
+
+	go s.serve(s.listener)
+	return nil
+}
+
+func (s *GrpcServer) listen(ctx context.Context) (net.Listener, error) {
+	address := s.addr.GetAddress()
+	for attempt := 1; ; attempt++ {
+		ln, err := net.Listen("tcp", address)
+		if err == nil {
+			return ln, nil
+		}
+		if s.listenRetry.MaxAttempts > 0 && attempt >= s.listenRetry.MaxAttempts {
+			return nil, errors.WithMessagef(err, "open grpc port %s", address)
+		}
+		log.Errorf(stdcodes.ModuleGrpcServiceStartError, "grpc server %s: open grpc port: %s, err: %v, retry after %s...",
+			s.name, address, err, s.listenRetry.Interval)
+		select {
+		case <-ctx.Done():
+			return nil, errors.WithMessagef(ctx.Err(), "open grpc port %s: %v", address, err)
+		case <-time.After(s.listenRetry.Interval):
+		}
+	}
 }
 
-func (s *GrpcServer) Start() {
-	log.Infof(stdcodes.ModuleGrpcServiceStart, "start grpc service on %s", s.listener.Addr().String())
-	if err := s.Serve(s.listener); err != nil && err != grpc.ErrServerStopped {
-		log.Fatalf(stdcodes.ModuleGrpcServiceStartError, "grpc serve: %v", err)
+func (s *GrpcServer) serve(ln net.Listener) {
+	defer close(s.done)
+
+	log.Infof(stdcodes.ModuleGrpcServiceStart, "start grpc service %s on %s", s.name, ln.Addr().String())
+	if err := s.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+		s.serveErr = err
+		log.Errorf(stdcodes.ModuleGrpcServiceStartError, "grpc service %s serve: %v", s.name, err)
 	} else {
-		log.Infof(stdcodes.ModuleGrpcServiceManualShutdown, "shutdown grpc service on %s", s.listener.Addr().String())
+		log.Infof(stdcodes.ModuleGrpcServiceManualShutdown, "shutdown grpc service %s on %s", s.name, ln.Addr().String())
+	}
+}
+
+// Done is closed when server stops serving or is shut down without Start, Err returns serving error then
+func (s *GrpcServer) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err waits until server stops serving, it returns error immediately if server is neither started nor shut down
+func (s *GrpcServer) Err() error {
+	s.startLock.Lock()
+	started := s.started
+	s.startLock.Unlock()
+	if !started {
+		return errors.Errorf("grpc server %s is not started", s.name)
 	}
+	<-s.done
+	return s.serveErr
 }
 
+// Shutdown gracefully stops server, if ctx is done before all calls are finished, server is stopped immediately
+// and ctx error is returned. Server which is not started can't be started after Shutdown
+func (s *GrpcServer) Shutdown(ctx context.Context) error {
+	s.startLock.Lock()
+	if !s.started {
+		s.started = true
+		close(s.done)
+	}
+	s.startLock.Unlock()
+	if s.tls != nil {
+		defer s.tls.Close()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// Deprecated: use UpdateEndpoints
 func (s *GrpcServer) UpdateHandlers(methodPrefix string, handlersStructs ...interface{}) error {
 	funcs, streams, err := resolveHandlers(methodPrefix, handlersStructs...)
 	if err != nil {
 		return err
 	}
-	s.service.functions = funcs
-	s.service.streamConsumers = streams
-
+	s.service.swapHandlers(newHandlerSet(funcs, streams, nil))
 	return nil
 }
 
-func StartBackendGrpcServer(addr structure.AddressConfiguration, service *DefaultService, opt ...grpc.ServerOption) {
-	lock.Lock()
-	defer lock.Unlock()
-
-	if server != nil {
-		log.Fatalf(stdcodes.ModuleGrpcServiceStartError, "grpc service has already started on %v", addr.GetAddress())
+// UpdateEndpoints atomically replaces service handlers and declares new routes, then waits until calls started
+// with previous handlers are finished or ctx is done. Module info passed to bootstrap DeclareMe should return
+// DefaultService.Descriptors to declare actual endpoints
+func (s *GrpcServer) UpdateEndpoints(ctx context.Context, descriptors []structure.EndpointDescriptor) error {
+	drain, err := s.service.UpdateDescriptors(descriptors)
+	if err != nil {
+		return err
 	}
-
-	var ln net.Listener
-	var err error
-	for ln, err = net.Listen("tcp", addr.GetAddress()); err != nil; {
-		log.Errorf(stdcodes.ModuleGrpcServiceStartError, "open grpc port: %s, err: %v, retry after 3 second...", addr.GetAddress(), err)
-		time.Sleep(time.Second * 3)
+	s.declaratorLock.Lock()
+	declarator := s.declarator
+	s.declaratorLock.Unlock()
+	if declarator != nil {
+		declarator.DeclareRoutes()
 	}
+	return drain(ctx)
+}
+
+func (s *GrpcServer) SetRoutesDeclarator(declarator RoutesDeclarator) {
+	s.declaratorLock.Lock()
+	defer s.declaratorLock.Unlock()
+	s.declarator = declarator
+}
 
-	server = newBackendGrpcServer(ln, service, opt...)
-	go server.Start()
+func StartBackendGrpcServer(addr structure.AddressConfiguration, service *DefaultService, opt ...grpc.ServerOption) {
+	startDefaultServer(NewGrpcServer(DefaultServerName, addr, service, WithGrpcServerOptions(opt...)))
 }
 
 func StartBackendGrpcServerOn(addr structure.AddressConfiguration, ln net.Listener, service *DefaultService, opt ...grpc.ServerOption) {
+	startDefaultServer(NewGrpcServer(DefaultServerName, addr, service, WithListener(ln), WithGrpcServerOptions(opt...)))
+}
+
+func startDefaultServer(srv *GrpcServer) {
 	lock.Lock()
 	defer lock.Unlock()
 
 	if server != nil {
-		log.Fatalf(stdcodes.ModuleGrpcServiceStartError, "grpc service has already started on %v", addr.GetAddress())
+		log.Fatalf(stdcodes.ModuleGrpcServiceStartError, "grpc service has already started on %v", srv.addr.GetAddress())
 	}
 
-	server = newBackendGrpcServer(ln, service, opt...)
-	go server.Start()
+	srv.SetRoutesDeclarator(routesDeclarator)
+	if err := srv.Start(context.Background()); err != nil {
+		log.Fatalf(stdcodes.ModuleGrpcServiceStartError, "start grpc service: %v", err)
+	}
+	server = srv
 }
 
 func StopGrpcServer() {
@@ -92,22 +300,48 @@ func StopGrpcServer() {
 	defer lock.Unlock()
 
 	if server != nil {
-		server.GracefulStop()
+		_ = server.Shutdown(context.Background())
 		server = nil
 	}
 }
 
+// Deprecated: use UpdateEndpoints
 func UpdateHandlers(methodPrefix string, handlersStructs ...interface{}) error {
 	lock.Lock()
 	defer lock.Unlock()
 
 	if server != nil {
-		return server.UpdateHandlers(methodPrefix, handlersStructs)
+		return server.UpdateHandlers(methodPrefix, handlersStructs...)
 	}
 
 	return errors.New("grpc server not initialized")
 }
 
+// UpdateEndpoints replaces handlers of started server, see GrpcServer.UpdateEndpoints
+func UpdateEndpoints(ctx context.Context, descriptors []structure.EndpointDescriptor) error {
+	lock.Lock()
+	srv := server
+	lock.Unlock()
+
+	if srv != nil {
+		return srv.UpdateEndpoints(ctx, descriptors)
+	}
+
+	return errors.New("grpc server not initialized")
+}
+
+// SetRoutesDeclarator sets declarator used by UpdateEndpoints of started and later started server,
+// bootstrap sets it automatically
+func SetRoutesDeclarator(declarator RoutesDeclarator) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	routesDeclarator = declarator
+	if server != nil {
+		server.SetRoutesDeclarator(declarator)
+	}
+}
+
 func ServerIsInitialized() bool {
 	lock.Lock()
 	defer lock.Unlock()
@@ -0,0 +1,35 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"google.golang.org/grpc"
+)
+
+// startTestServer starts server of service on random local port and returns its address
+func startTestServer(service *DefaultService) (structure.AddressConfiguration, *GrpcServer) {
+	srv := NewGrpcServer("test", structure.AddressConfiguration{IP: "127.0.0.1", Port: "0"}, service)
+	if err := srv.Start(context.Background()); err != nil {
+		panic(err)
+	}
+	return serverAddress(srv), srv
+}
+
+// startHandlerServer starts test server with single endpoint
+func startHandlerServer(path string, handler interface{}) (structure.AddressConfiguration, *GrpcServer) {
+	return startTestServer(NewDefaultService([]structure.EndpointDescriptor{{Path: path, Handler: handler}}))
+}
+
+// newTestClient creates insecure client of addresses, opts are applied after insecure dial option
+func newTestClient(addrs []structure.AddressConfiguration, opts ...RxOption) *RxGrpcClient {
+	cli := NewRxGrpcClient(append([]RxOption{WithDialOptions(grpc.WithInsecure())}, opts...)...)
+	cli.ReceiveAddressList(addrs)
+	return cli
+}
+
+func serverAddress(srv *GrpcServer) structure.AddressConfiguration {
+	parts := strings.Split(srv.Addr().String(), ":")
+	return structure.AddressConfiguration{IP: parts[0], Port: parts[1]}
+}
@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcServer_MultipleServers(t *testing.T) {
+	assert := assert.New(t)
+
+	localhost := structure.AddressConfiguration{IP: "127.0.0.1", Port: "0"}
+	inner := NewGrpcServer("inner", localhost, NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "name", Handler: func() string { return "inner" }},
+	}))
+	outer := NewGrpcServer("outer", localhost, NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "name", Handler: func() string { return "outer" }},
+	}))
+	assert.NoError(inner.Start(context.Background()))
+	defer inner.Stop()
+	assert.NoError(outer.Start(context.Background()))
+	defer outer.Stop()
+	assert.Error(inner.Start(context.Background()))
+
+	for _, srv := range []*GrpcServer{inner, outer} {
+		cli := newTestClient([]structure.AddressConfiguration{serverAddress(srv)})
+		name := ""
+		assert.NoError(cli.Invoke("name", 1, nil, &name))
+		assert.Equal(srv.Name(), name)
+		_ = cli.Close()
+	}
+}
+
+func TestGrpcServer_ListenRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	port := strings.Split(busy.Addr().String(), ":")[1]
+	addr := structure.AddressConfiguration{IP: "127.0.0.1", Port: port}
+
+	srv := NewGrpcServer("test", addr, NewDefaultService(nil),
+		WithListenRetryPolicy(ListenRetryPolicy{MaxAttempts: 2, Interval: 10 * time.Millisecond}))
+	assert.Error(srv.Start(context.Background()))
+	// server is not started, so Err doesn't wait
+	assert.Error(srv.Err())
+	assert.NoError(srv.Shutdown(context.Background()))
+	<-srv.Done()
+	assert.NoError(srv.Err())
+
+	srv = NewGrpcServer("test", addr, NewDefaultService(nil),
+		WithListenRetryPolicy(ListenRetryPolicy{Interval: 10 * time.Millisecond}))
+	time.AfterFunc(50*time.Millisecond, func() {
+		_ = busy.Close()
+	})
+	assert.NoError(srv.Start(context.Background()))
+	assert.NoError(srv.Shutdown(context.Background()))
+	<-srv.Done()
+	assert.NoError(srv.Err())
+}
+
+func TestGrpcServer_ShutdownTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	started := make(chan struct{})
+	srv := NewGrpcServer("test", structure.AddressConfiguration{IP: "127.0.0.1", Port: "0"},
+		NewDefaultService([]structure.EndpointDescriptor{
+			{Path: "slow", Handler: func() string {
+				close(started)
+				time.Sleep(time.Second)
+				return "ok"
+			}},
+		}))
+	assert.NoError(srv.Start(context.Background()))
+
+	cli := newTestClient([]structure.AddressConfiguration{serverAddress(srv)})
+	defer cli.Close()
+	result := make(chan error)
+	go func() {
+		result <- cli.Invoke("slow", 1, nil, nil)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(srv.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Equal(codes.Unavailable, status.Code(<-result))
+}
@@ -1,10 +1,14 @@
 package backend
 
 import (
+	"errors"
+
 	proto "github.com/golang/protobuf/ptypes/struct"
 	"github.com/integration-system/isp-lib/v2/isp"
 	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/integration-system/isp-lib/v2/validation"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -47,7 +51,13 @@ func WrapBody(value *proto.Value) *isp.Message {
 	return result
 }
 
+// ResolveError converts handler error to grpc status error, *Error found in chain is converted with details
+// and must be logged if it has cause, other not grpc errors are hidden behind Internal status
 func ResolveError(err error) (_ error, mustLog bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.GRPCStatus().Err(), e.cause != nil
+	}
 	s, isGrpcErr := status.FromError(err)
 	if isGrpcErr {
 		return s.Err(), false
@@ -56,5 +66,29 @@ func ResolveError(err error) (_ error, mustLog bool) {
 }
 
 func validate(ctx RequestCtx, mappedRequestBody interface{}) error {
-	return utils.Validate(mappedRequestBody)
+	err := validation.Validate(mappedRequestBody)
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	violations := make([]FieldViolation, len(errs))
+	for i, v := range errs {
+		violations[i] = FieldViolation{Field: v.Field, Description: v.Message}
+	}
+	return NewValidationError(violations...)
+}
+
+func metadataGetter(md metadata.MD) func(key string) string {
+	return func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+}
+
+func metadataSetter(md metadata.MD) func(key, value string) {
+	return func(key, value string) {
+		md.Set(key, value)
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"reflect"
 
+	"github.com/integration-system/isp-lib/v2/config"
 	"github.com/integration-system/isp-lib/v2/utils"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
@@ -27,14 +28,15 @@ type bootstrapConfiguration struct {
 	remoteConfigPtr  interface{}
 	remoteConfigType string
 
-	defaultRemoteConfigPath string
+	defaultRemoteConfigPath    string
+	validateRemoteConfigSchema bool
 
-	onLocalConfigLoad     *reflect.Value
-	onRemoteConfigReceive *reflect.Value
-	onSocketErrorReceive  *reflect.Value
-	onConfigErrorReceive  *reflect.Value
+	onLocalConfigLoad     func(localConfig interface{})
+	onRemoteConfigReceive func(newConfig, oldConfig interface{}) error
+	onSocketErrorReceive  func(errorMessage map[string]interface{})
+	onConfigErrorReceive  func(errorMessage string)
 	onRoutesReceive       routesConsumer
-	onLocalConfigChange   interface{}
+	onLocalConfigChange   func() // subscribes to local config changes
 	onShutdown            shutdownHandler
 	onModuleReady         func()
 
@@ -43,10 +45,12 @@ type bootstrapConfiguration struct {
 	// module name -> addresses
 	connectedModules map[string][]string
 	subscribedEvents map[string]func([]byte)
+	readiness        *readinessConditions
 
-	makeSocketConfig   socketConfigProducer
-	makeModuleInfo     moduleInfoProducer
-	declaratorAcquirer declaratorAcquirer
+	makeSocketConfig     socketConfigProducer
+	makeStandaloneConfig standaloneConfigProducer
+	makeModuleInfo       moduleInfoProducer
+	declaratorAcquirer   declaratorAcquirer
 }
 
 /**
@@ -55,7 +59,9 @@ type bootstrapConfiguration struct {
 func (cfg *bootstrapConfiguration) OnLocalConfigLoad(f interface{}) *bootstrapConfiguration {
 	rv, rt := reflect.ValueOf(f), reflect.TypeOf(f)
 	assertSingleParamFunc(rt, cfg.localConfigType)
-	cfg.onLocalConfigLoad = &rv
+	cfg.onLocalConfigLoad = func(localConfig interface{}) {
+		callFunc(&rv, localConfig)
+	}
 	return cfg
 }
 
@@ -65,7 +71,9 @@ func (cfg *bootstrapConfiguration) OnLocalConfigLoad(f interface{}) *bootstrapCo
 func (cfg *bootstrapConfiguration) OnLocalConfigChange(f interface{}) *bootstrapConfiguration {
 	rt := reflect.TypeOf(f)
 	assertTwoParamFunc(rt, cfg.localConfigType)
-	cfg.onLocalConfigChange = f
+	cfg.onLocalConfigChange = func() {
+		config.OnConfigChange(f)
+	}
 	return cfg
 }
 
@@ -75,7 +83,9 @@ func (cfg *bootstrapConfiguration) OnLocalConfigChange(f interface{}) *bootstrap
 func (cfg *bootstrapConfiguration) OnSocketErrorReceive(f interface{}) *bootstrapConfiguration {
 	rv, rt := reflect.ValueOf(f), reflect.TypeOf(f)
 	assertSingleParamFunc(rt, reflect.TypeOf(map[string]interface{}{}).String())
-	cfg.onSocketErrorReceive = &rv
+	cfg.onSocketErrorReceive = func(errorMessage map[string]interface{}) {
+		callFunc(&rv, errorMessage)
+	}
 	return cfg
 }
 
@@ -85,12 +95,15 @@ func (cfg *bootstrapConfiguration) OnSocketErrorReceive(f interface{}) *bootstra
 func (cfg *bootstrapConfiguration) OnConfigErrorReceive(f interface{}) *bootstrapConfiguration {
 	rv, rt := reflect.ValueOf(f), reflect.TypeOf(f)
 	assertSingleParamFunc(rt, "string")
-	cfg.onConfigErrorReceive = &rv
+	cfg.onConfigErrorReceive = func(errorMessage string) {
+		callFunc(&rv, errorMessage)
+	}
 	return cfg
 }
 
 /**
  * Add an event listener for the moment when an application received its configuration
+ * Listener may return error to reject received config, in that case module stays on previous config
  */
 func (cfg *bootstrapConfiguration) OnRemoteConfigReceive(f interface{}) *bootstrapConfiguration {
 	if cfg.remoteConfigType == "" {
@@ -99,7 +112,14 @@ func (cfg *bootstrapConfiguration) OnRemoteConfigReceive(f interface{}) *bootstr
 	}
 	rv, rt := reflect.ValueOf(f), reflect.TypeOf(f)
 	assertTwoParamFunc(rt, cfg.remoteConfigType)
-	cfg.onRemoteConfigReceive = &rv
+	returnsError := assertOptionalErrorResult(rt)
+	cfg.onRemoteConfigReceive = func(newConfig, oldConfig interface{}) error {
+		res := callFunc(&rv, newConfig, oldConfig)
+		if returnsError && !res[0].IsNil() {
+			return res[0].Interface().(error)
+		}
+		return nil
+	}
 	return cfg
 }
 
@@ -119,6 +139,16 @@ func (cfg *bootstrapConfiguration) SocketConfiguration(f socketConfigProducer) *
 	return cfg
 }
 
+/**
+ * Specify the standalone configuration builder function. In standalone mode module runs without config service:
+ * remote config, routes and required modules addresses are read from local files and reloaded on change.
+ * Standalone mode may also be enabled with APP_STANDALONE=true env
+ */
+func (cfg *bootstrapConfiguration) StandaloneConfiguration(f standaloneConfigProducer) *bootstrapConfiguration {
+	cfg.makeStandaloneConfig = f
+	return cfg
+}
+
 // set callback function which receive module declarator on startup
 func (cfg *bootstrapConfiguration) AcquireDeclarator(f declaratorAcquirer) *bootstrapConfiguration {
 	cfg.declaratorAcquirer = f
@@ -143,12 +173,25 @@ func (cfg *bootstrapConfiguration) RequireModule(moduleName string, consumer add
 	return cfg
 }
 
+// module is in not ready state until returned condition is set to ready
+// readiness conditions also gate /health/ready probe on metric server
+func (cfg *bootstrapConfiguration) NewReadinessCondition(name string) *ReadinessCondition {
+	return cfg.readiness.add(name)
+}
+
 // add path to remote config module
 func (cfg *bootstrapConfiguration) DefaultRemoteConfigPath(path string) *bootstrapConfiguration {
 	cfg.defaultRemoteConfigPath = path
 	return cfg
 }
 
+// received remote config is validated against its json schema before unmarshalling,
+// violations are sent to config service with ERROR_CONFIG event
+func (cfg *bootstrapConfiguration) ValidateRemoteConfigSchema() *bootstrapConfiguration {
+	cfg.validateRemoteConfigSchema = true
+	return cfg
+}
+
 // subscribe to event published from config service
 // note: data slice is reused, so for async handling or storing, data must be copied
 func (cfg *bootstrapConfiguration) SubscribeBroadcastEvent(event string, f func(data []byte)) *bootstrapConfiguration {
@@ -195,6 +238,7 @@ func ServiceBootstrap(localConfigPtr, remoteConfigPtr interface{}) *bootstrapCon
 		requiredModules:  make(map[string]*connectConsumer),
 		connectedModules: make(map[string][]string),
 		subscribedEvents: make(map[string]func([]byte)),
+		readiness:        newReadinessConditions(),
 	}
 	if remoteConfigPtr != nil {
 		b.remoteConfigPtr = remoteConfigPtr
@@ -0,0 +1,170 @@
+package bootstrap
+
+import (
+	"github.com/integration-system/isp-lib/v2/config"
+	"github.com/integration-system/isp-lib/v2/structure"
+)
+
+// Typed module description, L is local config type, R is remote config type.
+// Unlike ServiceBootstrap, callbacks signatures are checked at compile time
+type ServiceBootstrapT[L, R any] struct {
+	cfg *bootstrapConfiguration
+}
+
+/**
+ * Add an event listener for the moment when the local config for an application loaded
+ */
+func (b *ServiceBootstrapT[L, R]) OnLocalConfigLoad(f func(localConfig *L)) *ServiceBootstrapT[L, R] {
+	b.cfg.onLocalConfigLoad = func(localConfig interface{}) {
+		f(localConfig.(*L))
+	}
+	return b
+}
+
+/**
+ * Add an event listener for the moment when the local config for current application changed
+ */
+func (b *ServiceBootstrapT[L, R]) OnLocalConfigChange(f func(newConfig, oldConfig *L)) *ServiceBootstrapT[L, R] {
+	b.cfg.onLocalConfigChange = func() {
+		config.OnConfigChangeT(f)
+	}
+	return b
+}
+
+/**
+ * Add an event listener for the moment when the error message receive
+ */
+func (b *ServiceBootstrapT[L, R]) OnSocketErrorReceive(f func(errorMessage map[string]interface{})) *ServiceBootstrapT[L, R] {
+	b.cfg.onSocketErrorReceive = f
+	return b
+}
+
+/**
+ * Add an event listener for the moment when the config error message receive
+ */
+func (b *ServiceBootstrapT[L, R]) OnConfigErrorReceive(f func(errorMessage string)) *ServiceBootstrapT[L, R] {
+	b.cfg.onConfigErrorReceive = f
+	return b
+}
+
+/**
+ * Add an event listener for the moment when an application received its configuration
+ */
+func (b *ServiceBootstrapT[L, R]) OnRemoteConfigReceive(f func(newConfig, oldConfig *R)) *ServiceBootstrapT[L, R] {
+	return b.OnRemoteConfigApply(func(newConfig, oldConfig *R) error {
+		f(newConfig, oldConfig)
+		return nil
+	})
+}
+
+/**
+ * Add an event listener for the moment when an application received its configuration
+ * Returned error rejects received config, in that case module stays on previous config
+ */
+func (b *ServiceBootstrapT[L, R]) OnRemoteConfigApply(f func(newConfig, oldConfig *R) error) *ServiceBootstrapT[L, R] {
+	b.cfg.onRemoteConfigReceive = func(newConfig, oldConfig interface{}) error {
+		return f(newConfig.(*R), oldConfig.(*R))
+	}
+	return b
+}
+
+/**
+ * Add a hook for executing a code when an application is ready to be ended
+ */
+func (b *ServiceBootstrapT[L, R]) OnShutdown(f shutdownHandler) *ServiceBootstrapT[L, R] {
+	b.cfg.OnShutdown(f)
+	return b
+}
+
+/**
+ * Specify the socket builder function that creates a socket configuration
+ */
+func (b *ServiceBootstrapT[L, R]) SocketConfiguration(f func(localConfig *L) structure.SocketConfiguration) *ServiceBootstrapT[L, R] {
+	b.cfg.SocketConfiguration(func(localConfigPtr interface{}) structure.SocketConfiguration {
+		return f(localConfigPtr.(*L))
+	})
+	return b
+}
+
+/**
+ * Specify the standalone configuration builder function
+ */
+func (b *ServiceBootstrapT[L, R]) StandaloneConfiguration(f func(localConfig *L) structure.StandaloneConfiguration) *ServiceBootstrapT[L, R] {
+	b.cfg.StandaloneConfiguration(func(localConfigPtr interface{}) structure.StandaloneConfiguration {
+		return f(localConfigPtr.(*L))
+	})
+	return b
+}
+
+// set callback function which receive module declarator on startup
+func (b *ServiceBootstrapT[L, R]) AcquireDeclarator(f declaratorAcquirer) *ServiceBootstrapT[L, R] {
+	b.cfg.AcquireDeclarator(f)
+	return b
+}
+
+// provides callback function which return base module information
+func (b *ServiceBootstrapT[L, R]) DeclareMe(f func(localConfig *L) ModuleInfo) *ServiceBootstrapT[L, R] {
+	b.cfg.DeclareMe(func(localConfigPtr interface{}) ModuleInfo {
+		return f(localConfigPtr.(*L))
+	})
+	return b
+}
+
+// module is in not ready state until received routes from config-service
+func (b *ServiceBootstrapT[L, R]) RequireRoutes(f routesConsumer) *ServiceBootstrapT[L, R] {
+	b.cfg.RequireRoutes(f)
+	return b
+}
+
+// module is in not ready state until establish grpc connection with required modules
+func (b *ServiceBootstrapT[L, R]) RequireModule(moduleName string, consumer addressListConsumer, mustConnect bool) *ServiceBootstrapT[L, R] {
+	b.cfg.RequireModule(moduleName, consumer, mustConnect)
+	return b
+}
+
+// module is in not ready state until returned condition is set to ready
+func (b *ServiceBootstrapT[L, R]) NewReadinessCondition(name string) *ReadinessCondition {
+	return b.cfg.NewReadinessCondition(name)
+}
+
+// add path to remote config module
+func (b *ServiceBootstrapT[L, R]) DefaultRemoteConfigPath(path string) *ServiceBootstrapT[L, R] {
+	b.cfg.DefaultRemoteConfigPath(path)
+	return b
+}
+
+// received remote config is validated against its json schema before unmarshalling,
+// violations are sent to config service with ERROR_CONFIG event
+func (b *ServiceBootstrapT[L, R]) ValidateRemoteConfigSchema() *ServiceBootstrapT[L, R] {
+	b.cfg.ValidateRemoteConfigSchema()
+	return b
+}
+
+// subscribe to event published from config service
+// note: data slice is reused, so for async handling or storing, data must be copied
+func (b *ServiceBootstrapT[L, R]) SubscribeBroadcastEvent(event string, f func(data []byte)) *ServiceBootstrapT[L, R] {
+	b.cfg.SubscribeBroadcastEvent(event, f)
+	return b
+}
+
+// callback fires every time before MODULE:READY send to config service
+func (b *ServiceBootstrapT[L, R]) OnModuleReady(f func()) *ServiceBootstrapT[L, R] {
+	b.cfg.OnModuleReady(f)
+	return b
+}
+
+// starts module, block until interruption
+func (b *ServiceBootstrapT[L, R]) Run() {
+	b.cfg.Run()
+}
+
+// typed entry point to describe module, remoteConfig may be nil if module has no remote config
+func NewServiceBootstrap[L, R any](localConfig *L, remoteConfig *R) *ServiceBootstrapT[L, R] {
+	var remoteConfigPtr interface{}
+	if remoteConfig != nil {
+		remoteConfigPtr = remoteConfig
+	}
+	return &ServiceBootstrapT[L, R]{
+		cfg: ServiceBootstrap(localConfig, remoteConfigPtr),
+	}
+}
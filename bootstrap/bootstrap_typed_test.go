@@ -0,0 +1,165 @@
+package bootstrap
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/config"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceBootstrapT(t *testing.T) {
+	a := assert.New(t)
+	tmpDir := setupConfig(t, "127.0.0.1", "0")
+
+	var localConfig *Configuration
+	remoteConfigs := make(chan *RemoteConfig, 1)
+	b := NewServiceBootstrap(&Configuration{}, &RemoteConfig{}).
+		StandaloneConfiguration(func(_ *Configuration) structure.StandaloneConfiguration {
+			return structure.StandaloneConfiguration{
+				Enabled:          true,
+				RemoteConfigPath: filepath.Join(tmpDir, "default_remote_config.json"),
+			}
+		}).
+		DeclareMe(func(cfg *Configuration) ModuleInfo {
+			return makeDeclaration(cfg)
+		}).
+		OnLocalConfigLoad(func(cfg *Configuration) {
+			localConfig = cfg
+		}).
+		OnRemoteConfigReceive(func(newConfig, oldConfig *RemoteConfig) {
+			a.NotNil(oldConfig)
+			remoteConfigs <- newConfig
+		})
+
+	r := makeRunner(*b.cfg)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- r.run()
+	}()
+
+	select {
+	case remoteConfig := <-remoteConfigs:
+		a.Equal(_validRemoteConfig, *remoteConfig)
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("remote config is not received")
+	}
+	if a.NotNil(localConfig) {
+		a.Equal("test", localConfig.ModuleName)
+	}
+
+	r.onRunnerShutdown(r.ctx, emptySignal{})
+	a.NoError(<-errCh)
+}
+
+func TestRemoteConfigRollback(t *testing.T) {
+	a := assert.New(t)
+	tmpDir := setupConfig(t, "127.0.0.1", "0")
+	remoteConfigPath := filepath.Join(tmpDir, "default_remote_config.json")
+
+	type applyCall struct {
+		newConfig, oldConfig RemoteConfig
+	}
+	calls := make(chan applyCall, 10)
+	sections := make(chan string, 10)
+	config.OnRemoteSectionChange("something", func(newSection, _ string) {
+		select {
+		case sections <- newSection:
+		default:
+		}
+	})
+	b := NewServiceBootstrap(&Configuration{}, &RemoteConfig{}).
+		StandaloneConfiguration(func(_ *Configuration) structure.StandaloneConfiguration {
+			return structure.StandaloneConfiguration{Enabled: true, RemoteConfigPath: remoteConfigPath}
+		}).
+		DeclareMe(func(cfg *Configuration) ModuleInfo {
+			return makeDeclaration(cfg)
+		}).
+		OnRemoteConfigApply(func(newConfig, oldConfig *RemoteConfig) error {
+			calls <- applyCall{newConfig: *newConfig, oldConfig: *oldConfig}
+			if newConfig.Something == "rejected" {
+				return errors.New("rejected by module")
+			}
+			return nil
+		})
+
+	r := makeRunner(*b.cfg)
+	go func() {
+		_ = r.run()
+	}()
+	defer r.onRunnerShutdown(r.ctx, emptySignal{})
+
+	receive := func() applyCall {
+		select {
+		case call := <-calls:
+			return call
+		case <-time.After(timeoutValidConnect):
+			a.FailNow("remote config listener is not called")
+		}
+		return applyCall{}
+	}
+	a.Equal(_validRemoteConfig, receive().newConfig)
+
+	a.NoError(ioutil.WriteFile(remoteConfigPath, []byte(`{"something": "rejected"}`), 0666))
+	a.Equal("rejected", receive().newConfig.Something)
+	rollback := receive()
+	a.Equal(_validRemoteConfig, rollback.newConfig)
+	a.Equal("rejected", rollback.oldConfig.Something)
+	a.Equal(&_validRemoteConfig, config.GetRemote())
+	// section is notified only about applied config
+	a.Equal([]string{_validRemoteConfig.Something}, drainSections(sections))
+}
+
+func TestRemoteSectionChangeOnFirstConfig(t *testing.T) {
+	a := assert.New(t)
+	tmpDir := setupConfig(t, "127.0.0.1", "0")
+
+	sections := make(chan string, 1)
+	config.OnRemoteSectionChange("something", func(newSection, _ string) {
+		select {
+		case sections <- newSection:
+		default:
+		}
+	})
+	// initial config is equal to received one as if it was filled with defaults
+	initial := _validRemoteConfig
+	b := NewServiceBootstrap(&Configuration{}, &initial).
+		StandaloneConfiguration(func(_ *Configuration) structure.StandaloneConfiguration {
+			return structure.StandaloneConfiguration{
+				Enabled:          true,
+				RemoteConfigPath: filepath.Join(tmpDir, "default_remote_config.json"),
+			}
+		}).
+		DeclareMe(func(cfg *Configuration) ModuleInfo {
+			return makeDeclaration(cfg)
+		})
+
+	r := makeRunner(*b.cfg)
+	go func() {
+		_ = r.run()
+	}()
+	defer r.onRunnerShutdown(r.ctx, emptySignal{})
+
+	select {
+	case section := <-sections:
+		a.Equal(_validRemoteConfig.Something, section)
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("section subscriber is not called on first config")
+	}
+}
+
+func drainSections(sections <-chan string) []string {
+	result := make([]string, 0)
+	for {
+		select {
+		case section := <-sections:
+			result = append(result, section)
+		default:
+			return result
+		}
+	}
+}
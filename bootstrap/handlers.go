@@ -7,7 +7,6 @@ import (
 	"github.com/integration-system/isp-log/stdcodes"
 	"github.com/json-iterator/go"
 	"os"
-	"reflect"
 	"syscall"
 )
 
@@ -58,17 +57,17 @@ func handleRemoteConfiguration(remoteConfigChan chan []byte, event string) func(
 	}
 }
 
-func handleError(onSocketErrorReceive *reflect.Value, event string) func([]byte) {
+func handleError(onSocketErrorReceive func(map[string]interface{}), event string) func([]byte) {
 	return func(data []byte) {
 		var args map[string]interface{}
 		_ = json.Unmarshal(data, &args)
-		callFunc(onSocketErrorReceive, args)
+		onSocketErrorReceive(args)
 	}
 }
 
-func handleConfigError(onConfigErrorReceive *reflect.Value, event string) func([]byte) {
+func handleConfigError(onConfigErrorReceive func(string), event string) func([]byte) {
 	return func(data []byte) {
-		callFunc(onConfigErrorReceive, string(data))
+		onConfigErrorReceive(string(data))
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -22,6 +23,7 @@ import (
 	"github.com/integration-system/isp-lib/v2/config/schema"
 	"github.com/integration-system/isp-lib/v2/metric"
 	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/tlsconfig"
 	"github.com/integration-system/isp-lib/v2/utils"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
@@ -38,6 +40,7 @@ const (
 	ackMaxTimeout                               = 600 * time.Millisecond
 	defaultAckMaxTotalRetryTime                 = 10 * time.Second
 	defaultConnectionReadLimit            int64 = 4 << 20 // 4 MB
+	applyResultsBufferSize                      = 8
 )
 
 var (
@@ -56,6 +59,10 @@ type runner struct {
 	connectEventChan chan connectEvent
 	disconnectChan   chan struct{}
 	ackEventChan     chan ackEventMsg
+	// pending request to declare routes again, buffered to not block backend.UpdateEndpoints
+	redeclareChan chan struct{}
+	// results of received remote configs in order of receiving, nil in standalone mode
+	applyResultChan chan error
 
 	client                   etp.Client
 	connStrings              *RoundRobinStrings
@@ -65,8 +72,23 @@ type runner struct {
 	cancelCtx          func()
 	shutdownRunnerOnce sync.Once
 
-	socketConfig    structure.SocketConfiguration
-	configAddresses []structure.AddressConfiguration
+	socketConfig     structure.SocketConfiguration
+	socketTLS        *tlsconfig.Loader
+	configAddresses  []structure.AddressConfiguration
+	standaloneConfig structure.StandaloneConfiguration
+
+	// snapshot of moduleState for health probes
+	probeState atomic.Value
+	// true after first successfully applied remote config, used only in applying routine
+	remoteConfigApplied bool
+	// guards remoteConfigPtr replaced by applying routine
+	remoteConfigLock sync.RWMutex
+}
+
+type remoteConfigApplyTask struct {
+	cfg interface{}
+	// not nil if config is rejected before applying, it is reported in order with applied ones
+	err error
 }
 
 type moduleState struct {
@@ -74,12 +96,13 @@ type moduleState struct {
 	requiredModulesReady    bool
 	requiredSendReady       bool
 	routesReady             bool
+	conditionsReady         bool
 	moduleReady             bool
 	currentConnectedModules map[string]bool
 }
 
 func (t *moduleState) canSendModuleReady() bool {
-	if t.remoteConfigReady && t.requiredModulesReady && t.requiredSendReady && t.routesReady && !t.moduleReady {
+	if t.remoteConfigReady && t.requiredModulesReady && t.requiredSendReady && t.routesReady && t.conditionsReady && !t.moduleReady {
 		return true
 	}
 	return false
@@ -94,6 +117,7 @@ func makeRunner(cfg bootstrapConfiguration) *runner {
 		routesChan:             make(chan structure.RoutingConfig),
 		disconnectChan:         make(chan struct{}),
 		ackEventChan:           make(chan ackEventMsg),
+		redeclareChan:          make(chan struct{}, 1),
 		ctx:                    ctx,
 		cancelCtx:              cancelCtx,
 	}
@@ -109,6 +133,11 @@ func (b *runner) run() (ret error) {
 
 	b.initLocalConfig() //read local configuration, calls callback
 	b.initModuleInfo()  //set moduleInfo
+	b.initRemoteConfigDefaults()
+	b.initHealthProbes()
+	if b.initStandaloneConfig(); b.standaloneConfig.Enabled {
+		return b.runStandalone() //read configs and routes from files, never connect to config service
+	}
 	err := b.initSocketConfig()
 	if err != nil {
 		return fmt.Errorf("init socket configuration: %v", err)
@@ -120,47 +149,20 @@ func (b *runner) run() (ret error) {
 	b.client = client
 	b.initStatusMetrics() //add socket and required modules connections checkers in metrics
 
+	dec := &declarator{b.requestRoutesDeclaration}
+	backend.SetRoutesDeclarator(dec) //routes are declared again on backend.UpdateEndpoints
 	if b.declaratorAcquirer != nil {
-		b.declaratorAcquirer(&declarator{b.sendModuleDeclaration}) //provides module declarator to clients code
+		b.declaratorAcquirer(dec) //provides module declarator to clients code
 	}
 
-	go b.sendModuleConfigSchema() //create and send schema with default remote config
+	go b.sendModuleConfigSchema(b.moduleInfo.ModuleVersion) //create and send schema with default remote config
+
+	b.applyResultChan = make(chan error, applyResultsBufferSize)
+	go b.sendRemoteConfigApplyResults()
 
-	type remoteConfigApplyTask struct {
-		cfg    interface{}
-		rawCfg []byte
-	}
 	remoteConfigsCh := make(chan remoteConfigApplyTask, 1)
 	remoteConfigAppliedCh := make(chan struct{}, 1)
-	go func() {
-		for {
-			select {
-			case <-b.ctx.Done():
-				return
-			case task := <-remoteConfigsCh:
-				oldRemoteConfig := b.remoteConfigPtr
-
-				if utils.DEV {
-					log.WithMetadata(log.Metadata{"config": string(task.rawCfg)}).
-						Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config, started applying")
-				} else {
-					log.Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config, started applying")
-				}
-
-				if b.onRemoteConfigReceive != nil {
-					callFunc(b.onRemoteConfigReceive, task.cfg, oldRemoteConfig)
-				}
-				log.Info(stdcodes.ConfigServiceReceiveConfiguration, "remote config applied")
-
-				config.UnsafeSetRemote(task.cfg)
-				b.remoteConfigPtr = task.cfg
-				select {
-				case remoteConfigAppliedCh <- struct{}{}:
-				default:
-				}
-			}
-		}
-	}()
+	go b.applyRemoteConfigs(remoteConfigsCh, remoteConfigAppliedCh)
 
 	b.moduleState = b.initialState()
 	remoteConfigTimeoutChan := time.After(defaultRemoteConfigAwaitTimeout) //used for log WARN message
@@ -176,20 +178,20 @@ func (b *runner) run() (ret error) {
 			b.moduleState.moduleReady = true
 			initChan <- struct{}{}
 		}
+		b.probeState.Store(b.moduleState)
 
 		select {
 		case data := <-b.remoteConfigChan:
-			oldConfigCopy := deepcopy.Copy(b.remoteConfigPtr)
-			newRemoteConfig, rawCfg, err := config.PrepareRemoteConfig(oldConfigCopy, data)
+			newRemoteConfig, err := b.prepareRemoteConfig(data)
+			remoteConfigTimeoutChan = neverTriggerChan //stop flooding in logs
 			if err != nil {
-				return err
+				log.Errorf(stdcodes.ModuleInvalidRemoteConfig, "remote config rejected: %v", err)
+				remoteConfigsCh <- remoteConfigApplyTask{err: err}
+				continue
 			}
 			remoteConfigsCh <- remoteConfigApplyTask{
-				cfg:    newRemoteConfig,
-				rawCfg: rawCfg,
+				cfg: newRemoteConfig,
 			}
-
-			remoteConfigTimeoutChan = neverTriggerChan //stop flooding in logs
 		case <-remoteConfigTimeoutChan:
 			log.Error(stdcodes.RemoteConfigIsNotReceivedByTimeout, "remote config is not received by timeout")
 			remoteConfigTimeoutChan = time.After(defaultRemoteConfigAwaitTimeout)
@@ -203,34 +205,19 @@ func (b *runner) run() (ret error) {
 				b.moduleState.routesReady = b.onRoutesReceive(routers)
 			}
 		case e := <-b.connectEventChan:
-			if c, ok := b.requiredModules[e.module]; ok {
-				if ok := c.consumer(e.addressList); ok {
-					b.moduleState.currentConnectedModules[e.module] = true
-				}
-
-				ok := true
-				for e, consumer := range b.requiredModules {
-					val := b.moduleState.currentConnectedModules[e]
-					if !val && consumer.mustConnect {
-						ok = false
-						break
-					}
-				}
-				b.moduleState.requiredModulesReady = ok
-
-				addrList := make([]string, 0, len(e.addressList))
-				if b.moduleState.currentConnectedModules[e.module] {
-					for _, addr := range e.addressList {
-						addrList = append(addrList, addr.GetAddress())
-					}
-				}
-				b.connectedModules[e.module] = addrList
-			}
+			b.handleConnectEvent(e)
+		case <-b.readiness.changed:
+			b.moduleState.conditionsReady = b.readiness.allReady()
 		case <-initChan:
 			if b.onModuleReady != nil {
 				b.onModuleReady()
 			}
-			go b.sendModuleReady()
+			go b.sendModuleDeclaration(utils.ModuleReady, b.prepareModuleDeclaration())
+		case <-b.redeclareChan:
+			// before MODULE:READY routes are declared with it
+			if b.moduleState.moduleReady {
+				go b.sendModuleDeclaration(utils.ModuleUpdateRoutes, b.prepareModuleDeclaration())
+			}
 		case <-heartbeatCh.C:
 			if b.client == nil || b.client.Closed() {
 				continue
@@ -271,13 +258,129 @@ func (b *runner) run() (ret error) {
 				return nil
 			}
 			b.client = client
-			go b.sendModuleConfigSchema()
+			go b.sendModuleConfigSchema(b.moduleInfo.ModuleVersion)
 		case <-b.ctx.Done(): //return from main goroutine after shutdown signal
 			return nil
 		}
 	}
 }
 
+// applies prepared remote configs in background, signals to applied channel after each successfully applied one
+func (b *runner) applyRemoteConfigs(tasks <-chan remoteConfigApplyTask, applied chan<- struct{}) {
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case task := <-tasks:
+			err := task.err
+			if err == nil {
+				err = b.applyRemoteConfig(task)
+			}
+			b.reportRemoteConfigApplyResult(err)
+			if err != nil {
+				continue
+			}
+			select {
+			case applied <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// on rejection module stays on previous config, which is passed to listener again if it was applied before.
+// Sections are notified only after listener accepted config, notified sections are rolled back by config package
+func (b *runner) applyRemoteConfig(task remoteConfigApplyTask) error {
+	oldRemoteConfig := b.remoteConfigPtr
+
+	if utils.DEV {
+		log.WithMetadata(log.Metadata{"config": config.MaskSecrets(task.cfg)}).
+			Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config, started applying")
+	} else {
+		log.Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config, started applying")
+	}
+
+	err := b.callOnRemoteConfigReceive(task.cfg, oldRemoteConfig)
+	if err == nil {
+		err = b.notifyRemoteSections(task.cfg, oldRemoteConfig)
+	}
+	if err != nil {
+		log.Errorf(stdcodes.ModuleInvalidRemoteConfig, "remote config rejected: %v", err)
+		if b.remoteConfigApplied {
+			b.rollbackRemoteConfig(oldRemoteConfig, task.cfg)
+		}
+		return err
+	}
+	log.Info(stdcodes.ConfigServiceReceiveConfiguration, "remote config applied")
+
+	config.UnsafeSetRemote(task.cfg)
+	b.remoteConfigLock.Lock()
+	b.remoteConfigPtr = task.cfg
+	b.remoteConfigLock.Unlock()
+	b.remoteConfigApplied = true
+	return nil
+}
+
+// passes previous config to listener again after new one is rejected
+func (b *runner) rollbackRemoteConfig(oldConfig, rejectedConfig interface{}) {
+	if err := b.callOnRemoteConfigReceive(oldConfig, rejectedConfig); err != nil {
+		log.Errorf(stdcodes.ModuleInvalidRemoteConfig, "could not rollback to previous remote config: %v", err)
+	} else {
+		log.Info(stdcodes.ConfigServiceReceiveConfiguration, "rolled back to previous remote config")
+	}
+}
+
+func (b *runner) callOnRemoteConfigReceive(newConfig, oldConfig interface{}) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = errors2.WithStack(fmt.Errorf("recovered panic from remote config listener: %v", recovered))
+		}
+	}()
+	if b.onRemoteConfigReceive != nil {
+		return b.onRemoteConfigReceive(newConfig, oldConfig)
+	}
+	return nil
+}
+
+// calls listeners of changed sections
+func (b *runner) notifyRemoteSections(newConfig, oldConfig interface{}) error {
+	if !b.remoteConfigApplied {
+		// initial config is zero or default valued, all sections are reported as changed
+		oldConfig = nil
+	}
+	return config.NotifyRemoteSectionChanges(newConfig, oldConfig)
+}
+
+func (b *runner) handleConnectEvent(e connectEvent) {
+	c, ok := b.requiredModules[e.module]
+	if !ok {
+		return
+	}
+
+	if ok := c.consumer(e.addressList); ok {
+		b.moduleState.currentConnectedModules[e.module] = true
+	}
+
+	ready := true
+	for module, consumer := range b.requiredModules {
+		val := b.moduleState.currentConnectedModules[module]
+		if !val && consumer.mustConnect {
+			ready = false
+			break
+		}
+	}
+	b.moduleState.requiredModulesReady = ready
+
+	addrList := make([]string, 0, len(e.addressList))
+	if b.moduleState.currentConnectedModules[e.module] {
+		for _, addr := range e.addressList {
+			addrList = append(addrList, addr.GetAddress())
+		}
+	}
+	b.connectedModules[e.module] = addrList
+}
+
 func (b *runner) onRunnerShutdown(ctx context.Context, sig os.Signal) {
 	b.shutdownRunnerOnce.Do(func() {
 		log.Info(stdcodes.ModuleManualShutdown, "module shutting down now")
@@ -285,6 +388,7 @@ func (b *runner) onRunnerShutdown(ctx context.Context, sig os.Signal) {
 		if cancel := b.cancelCtx; cancel != nil {
 			cancel()
 		}
+		backend.SetRoutesDeclarator(nil)
 		if b.client != nil && !b.client.Closed() {
 			_ = b.client.Close()
 		}
@@ -299,11 +403,21 @@ func (b *runner) onRunnerShutdown(ctx context.Context, sig os.Signal) {
 
 func (b *runner) initLocalConfig() {
 	if b.onLocalConfigChange != nil {
-		config.OnConfigChange(b.onLocalConfigChange)
+		b.onLocalConfigChange()
 	}
 	b.localConfigPtr = config.InitConfigV2(b.localConfigPtr, false)
 	if b.onLocalConfigLoad != nil {
-		callFunc(b.onLocalConfigLoad, b.localConfigPtr)
+		b.onLocalConfigLoad(b.localConfigPtr)
+	}
+}
+
+// fills remote config with values from default tags before first config is received
+func (b *runner) initRemoteConfigDefaults() {
+	if b.remoteConfigPtr == nil {
+		return
+	}
+	if err := schema.SetDefaults(b.remoteConfigPtr); err != nil {
+		log.Warnf(stdcodes.ModuleDefaultRCReadError, "could not set remote config defaults: %v", err)
 	}
 }
 
@@ -322,6 +436,16 @@ func (b *runner) initSocketConfig() error {
 	if err != nil {
 		return fmt.Errorf("invalid socket configuration: %v", err)
 	}
+	if b.socketConfig.TLS != nil {
+		b.socketTLS, err = tlsconfig.NewLoader(*b.socketConfig.TLS)
+		if err != nil {
+			return fmt.Errorf("invalid socket tls configuration: %v", err)
+		}
+		go func() {
+			<-b.ctx.Done()
+			_ = b.socketTLS.Close()
+		}()
+	}
 	connectionStrings := makeWebsocketConnectionStrings(b.socketConfig, b.configAddresses)
 	b.connStrings = NewRoundRobinStrings(connectionStrings)
 
@@ -333,15 +457,37 @@ func (b *runner) initSocketConfig() error {
 	return nil
 }
 
+func (b *runner) initStandaloneConfig() {
+	if b.makeStandaloneConfig != nil {
+		b.standaloneConfig = b.makeStandaloneConfig(b.localConfigPtr)
+	}
+	if utils.EnvStandalone {
+		b.standaloneConfig.Enabled = true
+	}
+	if utils.EnvStandaloneRemoteConfigPath != "" {
+		b.standaloneConfig.RemoteConfigPath = utils.EnvStandaloneRemoteConfigPath
+	}
+	if utils.EnvStandaloneEnvironmentPath != "" {
+		b.standaloneConfig.EnvironmentPath = utils.EnvStandaloneEnvironmentPath
+	}
+	if b.standaloneConfig.RemoteConfigPath == "" {
+		b.standaloneConfig.RemoteConfigPath = b.defaultRemoteConfigPath
+	}
+}
+
 func (b *runner) initSocketConnection() etp.Client {
 	configAddress := b.connStrings.Get()
 	connectionReadLimit := defaultConnectionReadLimit
 	if b.socketConfig.ConnectionReadLimitKB > 0 {
 		connectionReadLimit = b.socketConfig.ConnectionReadLimitKB << 10
 	}
+	httpClient := &http.Client{}
+	if b.socketTLS != nil {
+		httpClient.Transport = b.socketTLS.HTTPTransport()
+	}
 	etpConfig := etp.Config{
 		ConnectionReadLimit:     connectionReadLimit,
-		HttpClient:              &http.Client{},
+		HttpClient:              httpClient,
 		WorkersNum:              1,
 		WorkersBufferMultiplier: 1,
 	}
@@ -366,7 +512,7 @@ func (b *runner) initSocketConnection() etp.Client {
 	if b.onConfigErrorReceive != nil {
 		client.On(utils.ConfigError, handleConfigError(b.onConfigErrorReceive, utils.ConfigError))
 	}
-	if b.remoteConfigPtr != nil {
+	if b.getRemoteConfig() != nil {
 		client.On(utils.ConfigSendConfigWhenConnected, handleRemoteConfiguration(b.remoteConfigChan, utils.ConfigSendConfigWhenConnected))
 		client.On(utils.ConfigSendConfigChanged, handleRemoteConfiguration(b.remoteConfigChan, utils.ConfigSendConfigChanged))
 		client.On(utils.ConfigSendConfigOnRequest, handleRemoteConfiguration(b.remoteConfigChan, utils.ConfigSendConfigOnRequest))
@@ -399,6 +545,27 @@ func (b *runner) initSocketConnection() etp.Client {
 	return client
 }
 
+func (b *runner) initHealthProbes() {
+	metric.SetLivenessChecker(func() (bool, interface{}) {
+		return b.ctx.Err() == nil, nil
+	})
+	metric.SetReadinessChecker(func() (bool, interface{}) {
+		state, _ := b.probeState.Load().(moduleState)
+		conditions := b.readiness.states()
+		ready := state.remoteConfigReady && state.requiredModulesReady && state.routesReady
+		for _, conditionReady := range conditions {
+			ready = ready && conditionReady
+		}
+		return ready, map[string]interface{}{
+			"remoteConfigReady":    state.remoteConfigReady,
+			"requiredModulesReady": state.requiredModulesReady,
+			"routesReady":          state.routesReady,
+			"moduleReady":          state.moduleReady,
+			"conditions":           conditions,
+		}
+	})
+}
+
 func (b *runner) initStatusMetrics() {
 	metric.InitStatusChecker("config-websocket", func() interface{} {
 		socketConfig := b.makeSocketConfig(b.localConfigPtr)
@@ -444,21 +611,32 @@ func (b *runner) sendModuleRequirements() {
 	}
 
 	bf := getDefaultBackoff(b.ctx)
-	b.ackEventChan <- ackEvent(b.client, utils.ModuleSendRequirements, requirements, bf)
+	b.sendAckEvent(ackEvent(b.client, utils.ModuleSendRequirements, requirements, bf))
 }
 
-func (b *runner) sendModuleDeclaration(eventType string) {
-	b.moduleInfo = b.makeModuleInfo(b.localConfigPtr)
+// asks main goroutine to declare routes again, never blocks caller
+func (b *runner) requestRoutesDeclaration(string) {
+	select {
+	case <-b.ctx.Done():
+	case b.redeclareChan <- struct{}{}:
+	default: //declaration is already requested
+	}
+}
 
-	declaration := b.getModuleDeclaration()
+// refreshes module info and makes module declaration, must be called from main goroutine
+func (b *runner) prepareModuleDeclaration() structure.BackendDeclaration {
+	b.moduleInfo = b.makeModuleInfo(b.localConfigPtr)
+	return b.getModuleDeclaration()
+}
 
+func (b *runner) sendModuleDeclaration(eventType string, declaration structure.BackendDeclaration) {
 	bf := getDefaultBackoff(b.ctx)
-	b.ackEventChan <- ackEvent(b.client, eventType, declaration, bf)
+	b.sendAckEvent(ackEvent(b.client, eventType, declaration, bf))
 }
 
-func (b *runner) sendModuleConfigSchema() {
-	s := schema.GenerateConfigSchema(b.remoteConfigPtr)
-	req := schema.ConfigSchema{Version: b.moduleInfo.ModuleVersion, Schema: s}
+func (b *runner) sendModuleConfigSchema(moduleVersion string) {
+	remoteConfig := b.getRemoteConfig()
+	req := schema.NewConfigSchema(moduleVersion, remoteConfig)
 
 	if defaultCfg, err := schema.ExtractConfig(b.defaultRemoteConfigPath); err != nil {
 		log.WithMetadata(log.Metadata{"path": b.defaultRemoteConfigPath}).
@@ -466,13 +644,110 @@ func (b *runner) sendModuleConfigSchema() {
 	} else {
 		req.DefaultConfig = defaultCfg
 	}
+	// default config file has priority over default tags
+	if req.DefaultConfig == nil {
+		if defaultCfg, err := schema.GenerateDefaultConfig(remoteConfig); err != nil {
+			log.Warnf(stdcodes.ModuleDefaultRCReadError, "could not generate default remote config: %v", err)
+		} else {
+			req.DefaultConfig = defaultCfg
+		}
+	}
 
 	bf := getDefaultBackoff(b.ctx)
-	b.ackEventChan <- ackEvent(b.client, utils.ModuleSendConfigSchema, req, bf)
+	b.sendAckEvent(ackEvent(b.client, utils.ModuleSendConfigSchema, req, bf))
+}
+
+// passes ack result to main goroutine, drops it after shutdown
+func (b *runner) sendAckEvent(msg ackEventMsg) {
+	select {
+	case b.ackEventChan <- msg:
+	case <-b.ctx.Done():
+	}
+}
+
+// returns last applied remote config, safe to call outside of applying routine
+func (b *runner) getRemoteConfig() interface{} {
+	b.remoteConfigLock.RLock()
+	defer b.remoteConfigLock.RUnlock()
+	return b.remoteConfigPtr
+}
+
+func (b *runner) prepareRemoteConfig(data []byte) (interface{}, error) {
+	oldConfigCopy := deepcopy.Copy(b.getRemoteConfig())
+	var opts []config.PrepareOption
+	if b.validateRemoteConfigSchema {
+		opts = append(opts, config.WithSchemaValidation())
+	}
+	newRemoteConfig, _, err := config.PrepareRemoteConfig(oldConfigCopy, data, opts...)
+	return newRemoteConfig, err
+}
+
+// passes apply result to sender, results are not reported in standalone mode
+func (b *runner) reportRemoteConfigApplyResult(applyErr error) {
+	if b.applyResultChan == nil {
+		return
+	}
+	select {
+	case b.applyResultChan <- applyErr:
+	case <-b.ctx.Done():
+	}
+}
+
+// sends apply results one by one, so config service receives them in order of receiving configs
+func (b *runner) sendRemoteConfigApplyResults() {
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case applyErr := <-b.applyResultChan:
+			b.sendRemoteConfigApplyResult(applyErr)
+		}
+	}
 }
 
-func (b *runner) sendModuleReady() {
-	b.sendModuleDeclaration(utils.ModuleReady)
+// reports config service whether received remote config applied or rejected
+func (b *runner) sendRemoteConfigApplyResult(applyErr error) {
+	result := RemoteConfigApplyResult{Applied: applyErr == nil}
+	var validationErr *schema.ValidationError
+	if applyErr != nil {
+		result.Error = applyErr.Error()
+		if errors.As(applyErr, &validationErr) {
+			result.Violations = validationErr.Violations
+		}
+	}
+	client := b.client
+	if client == nil || client.Closed() {
+		return
+	}
+	if validationErr != nil {
+		b.sendConfigViolations(client, validationErr)
+	}
+	bytes, err := json.Marshal(result)
+	if err != nil {
+		log.Errorf(stdcodes.ConfigServiceSendDataError, "marshal remote config apply result: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, ackMaxTimeout)
+	defer cancel()
+	if err := client.Emit(ctx, utils.ModuleConfigApplyResult, bytes); err != nil {
+		log.WithMetadata(log.Metadata{"event": utils.ModuleConfigApplyResult}).
+			Warnf(stdcodes.ConfigServiceSendDataError, "could not send remote config apply result: %v", err)
+	}
+}
+
+// sends schema violations of received remote config with ERROR_CONFIG event
+func (b *runner) sendConfigViolations(client etp.Client, validationErr *schema.ValidationError) {
+	bytes, err := json.Marshal(validationErr)
+	if err != nil {
+		log.Errorf(stdcodes.ConfigServiceSendDataError, "marshal remote config violations: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, ackMaxTimeout)
+	defer cancel()
+	if err := client.Emit(ctx, utils.ConfigError, bytes); err != nil {
+		log.WithMetadata(log.Metadata{"event": utils.ConfigError}).
+			Warnf(stdcodes.ConfigServiceSendDataError, "could not send remote config violations: %v", err)
+	}
 }
 
 // returns module initial state from bootstrap configuration
@@ -486,6 +761,7 @@ func (b *runner) initialState() (moduleState moduleState) {
 	}
 	moduleState.requiredModulesReady = len(b.requiredModules) == len(moduleState.currentConnectedModules)
 	moduleState.routesReady = b.onRoutesReceive == nil
+	moduleState.conditionsReady = b.readiness.allReady()
 	return
 }
 
@@ -510,6 +786,8 @@ func (b *runner) getModuleDeclaration() structure.BackendDeclaration {
 		}
 		moduleInfo.GrpcOuterAddress.IP = ip
 	}
+	// tls files are local to module
+	moduleInfo.GrpcOuterAddress.TLS = nil
 
 	requiredModules := make([]structure.ModuleDependency, 0, len(b.requiredModules))
 
@@ -3,6 +3,8 @@ package bootstrap
 import (
 	"context"
 	json2 "encoding/json"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -118,16 +120,64 @@ func TestDefaultValid(t *testing.T) {
 
 }
 
+// Повторное объявление маршрутов не должно блокировать вызывающего (backend.UpdateEndpoints)
+// ни до обработки запроса основной горутиной, ни после завершения работы модуля
+func TestDeclareRoutesNotBlocking(t *testing.T) {
+	b := makeRunner(*ServiceBootstrap(&Configuration{}, &RemoteConfig{}))
+	dec := &declarator{b.requestRoutesDeclaration}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dec.DeclareRoutes()
+		dec.DeclareRoutes() //coalesced with pending request
+		b.onRunnerShutdown(context.Background(), emptySignal{})
+		dec.DeclareRoutes()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeoutListen):
+		t.Fatal("DeclareRoutes blocked caller")
+	}
+}
+
+// Результаты применения конфигураций, в том числе отклоненных до применения,
+// передаются отправителю в порядке получения конфигураций
+func TestRemoteConfigApplyResultsOrder(t *testing.T) {
+	b := makeRunner(*ServiceBootstrap(&Configuration{}, &RemoteConfig{}))
+	defer b.onRunnerShutdown(context.Background(), emptySignal{})
+	b.applyResultChan = make(chan error, applyResultsBufferSize)
+	tasks := make(chan remoteConfigApplyTask, 1)
+	go b.applyRemoteConfigs(tasks, make(chan struct{}, 1))
+
+	rejected := []error{errors.New("first"), nil, errors.New("third")}
+	for _, err := range rejected {
+		tasks <- remoteConfigApplyTask{cfg: &RemoteConfig{}, err: err}
+	}
+	for _, expected := range rejected {
+		select {
+		case err := <-b.applyResultChan:
+			if err != expected {
+				t.Fatalf("expected apply result %v, got %v", expected, err)
+			}
+		case <-time.After(timeoutListen):
+			t.Fatal("apply result is not reported")
+		}
+	}
+}
+
 // В этом тесте производим отправку невалидного конфига в обработчике handleConfigSchema
 // Под невалидным понимается конфиг с иными полями
-// При получении невалидного конфига модуль завершает свою работу
-// фатальной ошибкой с описанием невалидных полей в конфигурации
+// При получении невалидного конфига модуль продолжает работу, не применяя конфиг,
+// и отправляет в сервис конфигурации событие с описанием причины отклонения конфигурации
 func Test_moduleReceivedAnotherConfig(t *testing.T) {
 	tb := (&testingBox{}).setDefault(t)
 
 	tb.expectedOrder = []eventType{
 		eventHandleConnect,
 		eventHandledConfigSchema,
+		eventHandleConfigApplyResult,
 	}
 	tb.handleServerFuncs.handleConfigSchema = func(conn etp.Conn, data []byte) []byte {
 		event := checkingEvent{typeEvent: eventHandledConfigSchema, conn: conn}
@@ -141,17 +191,18 @@ func Test_moduleReceivedAnotherConfig(t *testing.T) {
 		}
 		return []byte(utils.WsOkResponse)
 	}
-	tb.moduleFuncs.onRemoteConfigReceive = func(remoteConfig, _ *RemoteConfig) {
-		tb.t.Error("received from mock config-service RemoteConfig did not cause of terminate the module")
-	}
-	tb.testingFuncs.errorHandlingTestRun = func(err error, t *testing.T) {
-		if err == nil {
-			t.Errorf("Expected errror from run method, but received none")
-			return
-		}
-		if !strings.HasPrefix(err.Error(), "received invalid remote config:") {
-			t.Errorf("Expected errror with prefix: \n\"received invalid remote config:\"\nbut got:\n%v", err)
+	tb.handleServerFuncs.handleConfigApplyResult = func(conn etp.Conn, data []byte) {
+		event := checkingEvent{typeEvent: eventHandleConfigApplyResult, conn: conn, data: data}
+		result := RemoteConfigApplyResult{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			event.err = err
+		} else if result.Applied || !strings.HasPrefix(result.Error, "received invalid remote config:") {
+			event.err = fmt.Errorf("expected rejected config with reason, got: %s", data)
 		}
+		tb.checkingChan <- event
+	}
+	tb.moduleFuncs.onRemoteConfigReceive = func(remoteConfig, _ *RemoteConfig) {
+		tb.t.Error("invalid RemoteConfig from mock config-service was applied")
 	}
 	tb.testingFuncs.waitFullConnect = func(tb *testingBox) {
 		timeout := time.After(timeoutValidConnect)
@@ -164,13 +215,14 @@ func Test_moduleReceivedAnotherConfig(t *testing.T) {
 
 	tb.testingServersRun()
 	tb.testingListener()
+	tb.moduleRunner.onRunnerShutdown(context.Background(), emptySignal{})
 }
 
 // Проверяется положение: Если в процессе "рукопожатия" или после от isp-config-service в ответ возвращает не "ok"
 // или сервис становится недоступным, то модуль начинает процесс инициализации с самого начала.
 // Группа тестов проверяет поведение при получении отличающегося от utils.WsOkResponse ответа из хендлеров
 // handleConfigSchema handleModuleRequirements, handleModuleReady обрабатывающих события вызванные горутинами:
-// go b.sendModuleConfigSchema(), go b.sendModuleRequirements(), go b.sendModuleReady().
+// go b.sendModuleConfigSchema(), go b.sendModuleRequirements(), go b.sendModuleDeclaration(utils.ModuleReady, ...).
 // Если данные тесты возвращают ошибки, скорее всего не обрабатывается отличный от utils.WsOkResponse ответ,
 // возвращенный соответствующей функцией ackEvent
 // Попытки повторного подключения инициализируются функцией backoff.Retry(..), стандартное время следующего повтора
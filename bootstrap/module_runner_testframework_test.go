@@ -50,6 +50,7 @@ const (
 	eventHandleDisconnect
 	eventRemoteConfigReceive
 	eventRemoteConfigErrorReceive
+	eventHandleConfigApplyResult
 )
 
 type eventType uint
@@ -70,6 +71,8 @@ func (et eventType) String() string {
 		return "eventRemoteConfigReceive"
 	case eventRemoteConfigErrorReceive:
 		return "eventRemoteConfigErrorReceive"
+	case eventHandleConfigApplyResult:
+		return "eventHandleConfigApplyResult"
 	default:
 		return "(ERROR: Can't find type of event)"
 	}
@@ -107,6 +110,7 @@ type handleServerFuncs struct {
 	handleModuleRequirements func(conn etp.Conn, data []byte) []byte
 	handleConfigSchema       func(conn etp.Conn, data []byte) []byte
 	handleTestingEvent       func(conn etp.Conn, data []byte) []byte
+	handleConfigApplyResult  func(conn etp.Conn, data []byte)
 }
 
 type testingFuncs struct {
@@ -158,6 +162,7 @@ func (m *moduleFuncs) setDefault(checkingChan chan<- checkingEvent) {
 }
 
 func (h *handleServerFuncs) setDefault(tb *testingBox) {
+	h.handleConfigApplyResult = func(conn etp.Conn, data []byte) {}
 	h.handleConnect = func(conn etp.Conn) {
 		tb.checkingChan <- checkingEvent{typeEvent: eventHandleConnect, conn: conn}
 	}
@@ -270,7 +275,8 @@ func (s *mockConfigServer) subscribeAll(th handleServerFuncs) {
 		OnDisconnect(th.handleDisconnect).
 		OnWithAck(utils.ModuleReady, th.handleModuleReady).
 		OnWithAck(utils.ModuleSendRequirements, th.handleModuleRequirements).
-		OnWithAck(utils.ModuleSendConfigSchema, th.handleConfigSchema)
+		OnWithAck(utils.ModuleSendConfigSchema, th.handleConfigSchema).
+		On(utils.ModuleConfigApplyResult, th.handleConfigApplyResult)
 }
 
 func setupConfig(t *testing.T, configAddr, configPort string) string {
@@ -0,0 +1,85 @@
+package bootstrap
+
+import (
+	"sync"
+
+	"github.com/integration-system/isp-lib/v2/atomic"
+)
+
+// module defined condition (e.g. database migrated, cache warmed),
+// module is not ready and MODULE:READY is not sent until all conditions are ready
+type ReadinessCondition struct {
+	name   string
+	ready  *atomic.AtomicBool
+	notify func()
+}
+
+func (c *ReadinessCondition) Name() string {
+	return c.name
+}
+
+func (c *ReadinessCondition) IsReady() bool {
+	return c.ready.Get()
+}
+
+// changes condition state, may be called from any goroutine any number of times
+func (c *ReadinessCondition) SetReady(ready bool) {
+	if c.ready.Get() == ready {
+		return
+	}
+	c.ready.Set(ready)
+	c.notify()
+}
+
+type readinessConditions struct {
+	lock       sync.RWMutex
+	conditions []*ReadinessCondition
+	changed    chan struct{}
+}
+
+func (r *readinessConditions) add(name string) *ReadinessCondition {
+	c := &ReadinessCondition{
+		name:   name,
+		ready:  atomic.NewAtomicBool(false),
+		notify: r.notify,
+	}
+	r.lock.Lock()
+	r.conditions = append(r.conditions, c)
+	r.lock.Unlock()
+	return c
+}
+
+func (r *readinessConditions) notify() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (r *readinessConditions) allReady() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, c := range r.conditions {
+		if !c.IsReady() {
+			return false
+		}
+	}
+	return true
+}
+
+// condition name -> state
+func (r *readinessConditions) states() map[string]bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	states := make(map[string]bool, len(r.conditions))
+	for _, c := range r.conditions {
+		states[c.name] = c.IsReady()
+	}
+	return states
+}
+
+func newReadinessConditions() *readinessConditions {
+	return &readinessConditions{
+		changed: make(chan struct{}, 1),
+	}
+}
@@ -0,0 +1,53 @@
+package bootstrap
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessCondition(t *testing.T) {
+	a := assert.New(t)
+	tmpDir := setupConfig(t, "127.0.0.1", "0")
+
+	readyCh := make(chan struct{}, 1)
+	cfg := ServiceBootstrap(&Configuration{}, &RemoteConfig{}).
+		StandaloneConfiguration(func(_ interface{}) structure.StandaloneConfiguration {
+			return structure.StandaloneConfiguration{
+				Enabled:          true,
+				RemoteConfigPath: filepath.Join(tmpDir, "default_remote_config.json"),
+			}
+		}).
+		DeclareMe(makeDeclaration).
+		OnModuleReady(func() {
+			readyCh <- struct{}{}
+		})
+	condition := cfg.NewReadinessCondition("database")
+
+	r := makeRunner(*cfg)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- r.run()
+	}()
+
+	select {
+	case <-readyCh:
+		a.FailNow("module is ready before condition")
+	case <-time.After(100 * time.Millisecond):
+	}
+	a.False(r.readiness.states()["database"])
+
+	condition.SetReady(true)
+	select {
+	case <-readyCh:
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("module is not ready")
+	}
+	a.True(r.readiness.allReady())
+
+	r.onRunnerShutdown(r.ctx, emptySignal{})
+	a.NoError(<-errCh)
+}
@@ -0,0 +1,198 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/integration-system/isp-lib/v2/config/schema"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/utils"
+	log "github.com/integration-system/isp-log"
+	"github.com/integration-system/isp-log/stdcodes"
+)
+
+const (
+	standaloneModeEvent = 88
+)
+
+// content of standalone environment file, replaces data received from config service
+type StandaloneEnvironment struct {
+	// routes passed to RequireRoutes consumer
+	Routes structure.RoutingConfig `json:"routes"`
+	// module name -> addresses passed to RequireModule consumer
+	Modules map[string][]structure.AddressConfiguration `json:"modules"`
+}
+
+// runs module without config service, blocks until shutdown
+func (b *runner) runStandalone() error {
+	remoteConfigPath := b.standaloneConfig.RemoteConfigPath
+	environmentPath := b.standaloneConfig.EnvironmentPath
+	log.WithMetadata(log.Metadata{"remoteConfigPath": remoteConfigPath, "environmentPath": environmentPath}).
+		Info(standaloneModeEvent, "run module in standalone mode")
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create standalone files watcher: %v", err)
+	}
+	defer watcher.Close()
+	watchedFiles := make(map[string]bool)
+	for _, path := range []string{remoteConfigPath, environmentPath} {
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		watchedFiles[path] = true
+		// watch directory to handle editors and mounted volumes which replace files instead of writing
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("watch %s: %v", path, err)
+		}
+	}
+
+	if b.declaratorAcquirer != nil {
+		b.declaratorAcquirer(&declarator{func(eventType string) {
+			log.Debugf(standaloneModeEvent, "standalone mode: skip sending %s", eventType)
+		}})
+	}
+
+	remoteConfigsCh := make(chan remoteConfigApplyTask, 1)
+	remoteConfigAppliedCh := make(chan struct{}, 1)
+	go b.applyRemoteConfigs(remoteConfigsCh, remoteConfigAppliedCh)
+
+	b.moduleState = b.initialState()
+	b.moduleState.requiredSendReady = true
+	if b.getRemoteConfig() == nil {
+		b.moduleState.remoteConfigReady = true
+	} else {
+		task, err := b.readStandaloneRemoteConfig()
+		if err != nil {
+			return fmt.Errorf("read standalone remote config: %v", err)
+		}
+		remoteConfigsCh <- *task
+	}
+	env, err := b.readStandaloneEnvironment()
+	if err != nil {
+		return fmt.Errorf("read standalone environment: %v", err)
+	}
+	b.applyStandaloneEnvironment(env)
+
+	for {
+		if b.moduleState.canSendModuleReady() {
+			b.moduleState.moduleReady = true
+			if b.onModuleReady != nil {
+				b.onModuleReady()
+			}
+			log.Info(standaloneModeEvent, "module is ready in standalone mode")
+		}
+		b.probeState.Store(b.moduleState)
+
+		select {
+		case <-remoteConfigAppliedCh:
+			b.moduleState.remoteConfigReady = true
+		case <-b.readiness.changed:
+			b.moduleState.conditionsReady = b.readiness.allReady()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			path := filepath.Clean(event.Name)
+			if !watchedFiles[path] || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if path == filepath.Clean(remoteConfigPath) && b.getRemoteConfig() != nil {
+				task, err := b.readStandaloneRemoteConfig()
+				if err != nil {
+					log.WithMetadata(log.Metadata{"path": path}).
+						Errorf(stdcodes.ModuleInvalidRemoteConfig, "could not reload standalone remote config: %v", err)
+				} else {
+					remoteConfigsCh <- *task
+				}
+			}
+			if path == filepath.Clean(environmentPath) {
+				env, err := b.readStandaloneEnvironment()
+				if err != nil {
+					log.WithMetadata(log.Metadata{"path": path}).
+						Errorf(stdcodes.ConfigServiceInvalidDataReceived, "could not reload standalone environment: %v", err)
+				} else {
+					b.applyStandaloneEnvironment(env)
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Errorf(standaloneModeEvent, "standalone files watcher: %v", err)
+		case <-b.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (b *runner) readStandaloneRemoteConfig() (*remoteConfigApplyTask, error) {
+	path := b.standaloneConfig.RemoteConfigPath
+	if path == "" {
+		return nil, errors.New("remote config path is not specified")
+	}
+	data, err := schema.ExtractConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	newRemoteConfig, err := b.prepareRemoteConfig(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteConfigApplyTask{cfg: newRemoteConfig}, nil
+}
+
+func (b *runner) readStandaloneEnvironment() (*StandaloneEnvironment, error) {
+	env := &StandaloneEnvironment{}
+	path := b.standaloneConfig.EnvironmentPath
+	if path == "" {
+		return env, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err = yamlToJson(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %v", path, err)
+	}
+	if err := utils.Validate(env.Routes); err != nil {
+		return nil, fmt.Errorf("invalid routes: %v", err)
+	}
+	return env, nil
+}
+
+// passes file content to consumers the same way as events from config service
+func (b *runner) applyStandaloneEnvironment(env *StandaloneEnvironment) {
+	if b.onRoutesReceive != nil {
+		routes := env.Routes
+		if routes == nil {
+			routes = structure.RoutingConfig{}
+		}
+		log.WithMetadata(log.Metadata{"total_modules": len(routes)}).
+			Info(stdcodes.ConfigServiceReceiveRoutes, "read standalone routes")
+		b.moduleState.routesReady = b.onRoutesReceive(routes)
+	}
+	for module := range b.requiredModules {
+		addressList, ok := env.Modules[module]
+		if !ok {
+			log.WithMetadata(log.Metadata{"module": module}).
+				Warn(standaloneModeEvent, "required module addresses are not specified in standalone environment")
+			continue
+		}
+		log.WithMetadata(log.Metadata{"module": module, "addresses": addressList}).
+			Info(stdcodes.ConfigServiceReceiveRequiredModuleAddress, "read standalone required module address list")
+		b.handleConnectEvent(connectEvent{module: module, addressList: addressList})
+	}
+}
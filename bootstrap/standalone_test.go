@@ -0,0 +1,92 @@
+package bootstrap
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/stretchr/testify/assert"
+)
+
+const standaloneEnvironment = `
+routes:
+  - moduleName: other
+    endpoints:
+      - path: other/api/method
+modules:
+  somemodule:
+    - ip: 127.0.0.1
+      port: "9999"
+`
+
+func TestStandaloneMode(t *testing.T) {
+	a := assert.New(t)
+	tmpDir := setupConfig(t, "127.0.0.1", "0")
+	remoteConfigPath := filepath.Join(tmpDir, "default_remote_config.json")
+	environmentPath := filepath.Join(tmpDir, "environment.yml")
+	a.NoError(ioutil.WriteFile(environmentPath, []byte(standaloneEnvironment), 0666))
+
+	remoteConfigs := make(chan RemoteConfig, 2)
+	routesCh := make(chan structure.RoutingConfig, 1)
+	addressesCh := make(chan []structure.AddressConfiguration, 1)
+	readyCh := make(chan struct{}, 1)
+	cfg := ServiceBootstrap(&Configuration{}, &RemoteConfig{}).
+		StandaloneConfiguration(func(_ interface{}) structure.StandaloneConfiguration {
+			return structure.StandaloneConfiguration{
+				Enabled:          true,
+				RemoteConfigPath: remoteConfigPath,
+				EnvironmentPath:  environmentPath,
+			}
+		}).
+		DeclareMe(makeDeclaration).
+		OnRemoteConfigReceive(func(remoteConfig, _ *RemoteConfig) {
+			remoteConfigs <- *remoteConfig
+		}).
+		RequireRoutes(func(routes structure.RoutingConfig) bool {
+			routesCh <- routes
+			return true
+		}).
+		RequireModule("somemodule", func(list []structure.AddressConfiguration) bool {
+			addressesCh <- list
+			return true
+		}, true).
+		OnModuleReady(func() {
+			readyCh <- struct{}{}
+		})
+
+	r := makeRunner(*cfg)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- r.run()
+	}()
+
+	select {
+	case remoteConfig := <-remoteConfigs:
+		a.Equal(_validRemoteConfig, remoteConfig)
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("remote config is not received")
+	}
+	routes := <-routesCh
+	if a.Len(routes, 1) {
+		a.Equal("other", routes[0].ModuleName)
+	}
+	a.Equal([]structure.AddressConfiguration{{IP: "127.0.0.1", Port: "9999"}}, <-addressesCh)
+	select {
+	case <-readyCh:
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("module is not ready")
+	}
+
+	a.NoError(ioutil.WriteFile(remoteConfigPath, []byte(`{"something": "changed"}`), 0666))
+	select {
+	case remoteConfig := <-remoteConfigs:
+		a.Equal("changed", remoteConfig.Something)
+	case <-time.After(timeoutValidConnect):
+		a.FailNow("remote config is not reloaded")
+	}
+
+	r.onRunnerShutdown(r.ctx, emptySignal{})
+	a.NoError(<-errCh)
+}
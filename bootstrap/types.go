@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 
+	"github.com/integration-system/isp-lib/v2/config/schema"
 	"github.com/integration-system/isp-lib/v2/structure"
 	"github.com/integration-system/isp-lib/v2/utils"
 )
@@ -28,9 +29,19 @@ func (r ModuleRequirements) IsEmpty() bool {
 	return len(r.RequiredModules) == 0 && !r.RequireRoutes
 }
 
+// result of applying received remote config, sent to config service
+type RemoteConfigApplyResult struct {
+	Applied    bool               `json:"applied"`
+	Error      string             `json:"error,omitempty"`
+	Violations []schema.Violation `json:"violations,omitempty"`
+}
+
 // invoked once, returns config service address
 type socketConfigProducer func(localConfigPtr interface{}) structure.SocketConfiguration
 
+// invoked once, returns settings of running without config service
+type standaloneConfigProducer func(localConfigPtr interface{}) structure.StandaloneConfiguration
+
 // invoked once before module shutdown
 type shutdownHandler func(ctx context.Context, sig os.Signal)
 
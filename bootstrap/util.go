@@ -21,9 +21,12 @@ import (
 	"github.com/integration-system/isp-lib/v2/utils"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
+	"gopkg.in/yaml.v2"
 	"nhooyr.io/websocket"
 )
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 func assertSingleParamFunc(rt reflect.Type, expectingType string) {
 	if rt.Kind() != reflect.Func ||
 		rt.NumIn() != 1 ||
@@ -41,13 +44,52 @@ func assertTwoParamFunc(rt reflect.Type, expectingType string) {
 	}
 }
 
-func callFunc(f *reflect.Value, args ...interface{}) {
+// returns true if function returns single error, false if function returns nothing
+func assertOptionalErrorResult(rt reflect.Type) bool {
+	switch {
+	case rt.NumOut() == 0:
+		return false
+	case rt.NumOut() == 1 && rt.Out(0) == errorType:
+		return true
+	default:
+		panic(fmt.Errorf("expecting function without results or with single error result"))
+	}
+}
+
+func callFunc(f *reflect.Value, args ...interface{}) []reflect.Value {
 	values := make([]reflect.Value, len(args))
 	for i, v := range args {
 		values[i] = reflect.ValueOf(v)
 	}
 
-	f.Call(values)
+	return f.Call(values)
+}
+
+// converts YAML (or JSON as its subset) document to JSON to reuse json tags of structures
+func yamlToJson(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalizeYamlValue(value))
+}
+
+func normalizeYamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = normalizeYamlValue(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeYamlValue(val)
+		}
+		return v
+	default:
+		return v
+	}
 }
 
 func must(err error) {
@@ -118,7 +160,7 @@ func makeWebsocketConnectionStrings(sc structure.SocketConfiguration, addrs []st
 		connectionString := getWsUrl(
 			addr.IP,
 			addr.Port,
-			sc.Secure,
+			sc.Secure || sc.TLS != nil,
 			sc.UrlParams,
 		)
 		connStrings = append(connStrings, connectionString)
@@ -14,7 +14,7 @@ import (
 
 	"github.com/asaskevich/govalidator"
 	"github.com/fsnotify/fsnotify"
-	"github.com/integration-system/bellows"
+	"github.com/integration-system/isp-lib/v2/config/schema"
 	"github.com/integration-system/isp-lib/v2/structure"
 	"github.com/integration-system/isp-lib/v2/utils"
 	log "github.com/integration-system/isp-log"
@@ -35,7 +35,7 @@ var (
 	remoteConfigInstance atomic.Value
 
 	startWatching  = sync.Once{}
-	onChangeFunc   interface{}
+	onChangeFunc   func(newConfig, oldConfig interface{})
 	errInvalidFunc = errors.New("expecting func with two pointers to local config type")
 
 	reloadSig = syscall.SIGHUP
@@ -105,12 +105,12 @@ func InitConfigV2(localConfig interface{}, callOnChangeHandler bool) interface{}
 
 // Deprecated: use PrepareRemoteConfig and UnsafeSetRemote instead
 func InitRemoteConfig(configuration interface{}, remoteConfig []byte) (interface{}, error) {
-	newConfiguration, newRemoteConfig, err := PrepareRemoteConfig(configuration, remoteConfig)
+	newConfiguration, _, err := PrepareRemoteConfig(configuration, remoteConfig)
 	if err != nil {
 		return nil, err
 	}
 	if utils.DEV {
-		log.WithMetadata(log.Metadata{"config": string(newRemoteConfig)}).
+		log.WithMetadata(log.Metadata{"config": MaskSecrets(newConfiguration)}).
 			Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config")
 	} else {
 		log.Info(stdcodes.ConfigServiceReceiveConfiguration, "received remote config")
@@ -121,24 +121,62 @@ func InitRemoteConfig(configuration interface{}, remoteConfig []byte) (interface
 	return newConfiguration, nil
 }
 
-func PrepareRemoteConfig(configuration interface{}, remoteConfig []byte) (interface{}, []byte, error) {
-	newRemoteConfig, err := overrideConfigurationFromEnv(remoteConfig, RemoteConfigEnvPrefix)
+type prepareOptions struct {
+	validateSchema bool
+}
+
+type PrepareOption func(opts *prepareOptions)
+
+// WithSchemaValidation enables validation of remote config json against schema generated from configuration type,
+// violations are returned as *schema.ValidationError
+func WithSchemaValidation() PrepareOption {
+	return func(opts *prepareOptions) {
+		opts.validateSchema = true
+	}
+}
+
+func PrepareRemoteConfig(configuration interface{}, remoteConfig []byte, opts ...PrepareOption) (interface{}, []byte, error) {
+	options := prepareOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	newRemoteConfig, overrides, err := overrideConfigurationFromEnv(remoteConfig, RemoteConfigEnvPrefix, reflect.TypeOf(configuration))
 	if err != nil {
 		if utils.DEV {
-			return nil, nil, fmt.Errorf("could not override remote config via env: %v\nconfig=%s", err, remoteConfig)
+			masked := maskRawSecrets(remoteConfig, reflect.TypeOf(configuration))
+			return nil, nil, fmt.Errorf("could not override remote config via env: %v\nconfig=%s", err, masked)
 		} else {
 			return nil, nil, fmt.Errorf("could not override remote config via env: %v", err)
 		}
 	}
 
+	resolvedRemoteConfig, err := ResolveSecrets(newRemoteConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not resolve remote config secrets: %v", err)
+	}
+
+	if options.validateSchema {
+		s := schema.NewConfigSchema("", configuration)
+		if err := s.Validate(resolvedRemoteConfig); err != nil {
+			return nil, nil, fmt.Errorf("received invalid remote config: %w", err)
+		}
+	}
+
 	newConfiguration := reflect.New(reflect.TypeOf(configuration).Elem()).Interface()
-	if err := json.Unmarshal(newRemoteConfig, newConfiguration); err != nil {
+	if err := json.Unmarshal(resolvedRemoteConfig, newConfiguration); err != nil {
 		return nil, nil, fmt.Errorf("received invalid remote config: %v", err)
 	}
 	if err := validateConfig(newConfiguration); err != nil {
 		return nil, nil, fmt.Errorf("received invalid remote config: %v", err)
 	}
 
+	if len(overrides) > 0 {
+		log.WithMetadata(log.Metadata{"overrides": EnvOverrides(overrides).Paths()}).
+			Info(stdcodes.ConfigServiceReceiveConfiguration, "remote config overridden via env")
+	}
+	lastRemoteOverrides.Store(overrides)
+
 	return newConfiguration, newRemoteConfig, nil
 }
 
@@ -153,9 +191,27 @@ func OnConfigChange(f interface{}) {
 	if rt.Kind() != reflect.Func || rt.NumIn() != 2 {
 		panic(errInvalidFunc)
 	}
+	rv := reflect.ValueOf(f)
+	newCfgType := rt.In(0).String()
+	oldCfgType := rt.In(1).String()
+	subscribeConfigChange(func(newConfig, oldConfig interface{}) {
+		configType := reflect.TypeOf(newConfig).String()
+		if newCfgType != oldCfgType || newCfgType != configType {
+			panic(errInvalidFunc)
+		}
+		rv.Call([]reflect.Value{reflect.ValueOf(newConfig), reflect.ValueOf(oldConfig)})
+	})
+}
 
-	onChangeFunc = f
+// Typed variant of OnConfigChange, T is local config type
+func OnConfigChangeT[T any](f func(newConfig, oldConfig *T)) {
+	subscribeConfigChange(func(newConfig, oldConfig interface{}) {
+		f(newConfig.(*T), oldConfig.(*T))
+	})
+}
 
+func subscribeConfigChange(f func(newConfig, oldConfig interface{})) {
+	onChangeFunc = f
 	startWatching.Do(func() {
 		viper.WatchConfig()
 		viper.OnConfigChange(func(in fsnotify.Event) {
@@ -195,6 +251,7 @@ func reloadConfig() {
 func readLocalConfig(config interface{}) error {
 	squashStructsDecoderOption := func(dc *mapstructure.DecoderConfig) {
 		dc.Squash = true
+		dc.DecodeHook = localConfigDecodeHook()
 	}
 	if err := viper.ReadInConfig(); err != nil {
 		return fmt.Errorf("read local config file: %v", err)
@@ -209,20 +266,10 @@ func handleConfigChange(newConfig, oldConfig interface{}) {
 		return
 	}
 
-	rv := reflect.ValueOf(onChangeFunc)
-	rt := rv.Type()
-	configType := reflect.TypeOf(newConfig).String()
-	newCfgType := rt.In(0).String()
-	oldCfgType := rt.In(1).String()
-	if newCfgType == oldCfgType && newCfgType == configType {
-		if oldConfig == nil {
-			oldConfig = newConfig
-		}
-		args := []reflect.Value{reflect.ValueOf(newConfig), reflect.ValueOf(oldConfig)}
-		rv.Call(args)
-	} else {
-		panic(errInvalidFunc)
+	if oldConfig == nil {
+		oldConfig = newConfig
 	}
+	onChangeFunc(newConfig, oldConfig)
 }
 
 func validateConfig(cfg interface{}) error {
@@ -244,42 +291,6 @@ func validateConfig(cfg interface{}) error {
 	}
 }
 
-func overrideConfigurationFromEnv(src []byte, envPrefix string) ([]byte, error) {
-	envPrefix = envPrefix + "_"
-	overrides := getEnvOverrides(envPrefix)
-	if len(overrides) == 0 {
-		return src, nil
-	}
-
-	m := make(map[string]interface{})
-	err := json.Unmarshal(src, &m)
-	if err != nil {
-		return nil, fmt.Errorf("unmarshal to map: %v", err)
-	}
-
-	m = bellows.Flatten(m)
-	flattenMap := make(map[string]interface{}, len(m))
-	for k, v := range m {
-		flattenMap[strings.ToLower(k)] = v
-	}
-
-	for path, val := range overrides {
-		if newValue, err := castString(val); err != nil {
-			return nil, fmt.Errorf("could not override remote config variable %s, new value: %v, err: %v", path, val, err)
-		} else {
-			flattenMap[path] = newValue
-		}
-	}
-
-	expandedMap := bellows.Expand(flattenMap)
-	bytes, err := json.Marshal(expandedMap)
-	if err != nil {
-		return nil, fmt.Errorf("marhal to json: %v", err)
-	}
-
-	return bytes, nil
-}
-
 type CommonLocalConfig struct {
 	ModuleName           string                         `valid:"required~Required"`
 	ConfigServiceAddress structure.AddressConfiguration `valid:"required~Required"`
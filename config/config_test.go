@@ -1,10 +1,13 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/integration-system/isp-lib/v2/config/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,6 +46,11 @@ func TestRemoteConfigOverride(t *testing.T) {
 	assert.Nil(os.Setenv(RemoteConfigEnvPrefix+"_C", "false#{bool}"))
 	assert.Nil(os.Setenv(RemoteConfigEnvPrefix+"_D.CAMELCASE", "test2#{string}"))
 	assert.Nil(os.Setenv(RemoteConfigEnvPrefix+"_D.V", "test5#{string}"))
+	defer func() {
+		for _, name := range []string{"_A", "_B", "_C", "_D.CAMELCASE", "_D.V"} {
+			_ = os.Unsetenv(RemoteConfigEnvPrefix + name)
+		}
+	}()
 
 	ptr, err := InitRemoteConfig(&original, bytes)
 	if err != nil {
@@ -53,6 +61,45 @@ func TestRemoteConfigOverride(t *testing.T) {
 	assert.Equal(expect, original)
 }
 
+func TestPrepareRemoteConfigWithSchemaValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	type Config struct {
+		Address string `valid:"required"`
+		Port    int
+	}
+
+	cfg, _, err := PrepareRemoteConfig(&Config{}, []byte(`{"address":"localhost","port":80}`), WithSchemaValidation())
+	assert.NoError(err)
+	assert.Equal(&Config{Address: "localhost", Port: 80}, cfg)
+
+	_, _, err = PrepareRemoteConfig(&Config{}, []byte(`{"port":"80","host":"localhost"}`), WithSchemaValidation())
+	validationErr := &schema.ValidationError{}
+	if assert.True(errors.As(err, &validationErr)) {
+		paths := make([]string, 0)
+		for _, violation := range validationErr.Violations {
+			paths = append(paths, violation.Path)
+		}
+		assert.Equal([]string{"address", "host", "port"}, paths)
+	}
+}
+
+func TestPrepareRemoteConfigDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	type Config struct {
+		Timeout time.Duration
+		Delay   time.Duration
+	}
+
+	cfg, _, err := PrepareRemoteConfig(&Config{}, []byte(`{"timeout":"1m30s","delay":1000}`), WithSchemaValidation())
+	assert.NoError(err)
+	assert.Equal(&Config{Timeout: 90 * time.Second, Delay: time.Microsecond}, cfg)
+
+	_, _, err = PrepareRemoteConfig(&Config{}, []byte(`{"timeout":"1 minute"}`))
+	assert.Error(err)
+}
+
 func TestInitRemoteConfig(t *testing.T) {
 	assert := assert.New(t)
 	cleanupGlobals()
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const pathSeparator = "."
+
+// Change of single remote config value, Path is dot separated json path, array elements are addressed by index
+type Change struct {
+	Path     string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// Diff is sorted by path list of changed leaf values
+type Diff []Change
+
+// Changed returns true if value by path or any nested value changed, path is case insensitive
+func (d Diff) Changed(path string) bool {
+	path = strings.ToLower(path)
+	for _, change := range d {
+		changePath := strings.ToLower(change.Path)
+		if path == "" || changePath == path ||
+			strings.HasPrefix(changePath, path+pathSeparator) ||
+			strings.HasPrefix(path, changePath+pathSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Diff) Paths() []string {
+	paths := make([]string, len(d))
+	for i, change := range d {
+		paths[i] = change.Path
+	}
+	return paths
+}
+
+// DiffRemoteConfigs compares json representations of two configs, nil config is treated as empty object
+func DiffRemoteConfigs(oldConfig, newConfig interface{}) (Diff, error) {
+	oldMap, err := toJsonValue(oldConfig)
+	if err != nil {
+		return nil, fmt.Errorf("convert old config: %v", err)
+	}
+	newMap, err := toJsonValue(newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("convert new config: %v", err)
+	}
+
+	oldFlatten := make(map[string]interface{})
+	flattenJsonValue("", oldMap, oldFlatten)
+	newFlatten := make(map[string]interface{})
+	flattenJsonValue("", newMap, newFlatten)
+
+	diff := make(Diff, 0)
+	for path, newValue := range newFlatten {
+		oldValue, ok := oldFlatten[path]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			diff = append(diff, Change{Path: path, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for path, oldValue := range oldFlatten {
+		if _, ok := newFlatten[path]; !ok {
+			diff = append(diff, Change{Path: path, OldValue: oldValue})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Path < diff[j].Path
+	})
+	return diff, nil
+}
+
+type sectionSubscriber struct {
+	path   string
+	handle func(newConfig, oldConfig interface{}) error
+}
+
+var (
+	sectionSubscribers     []sectionSubscriber
+	sectionSubscribersLock sync.RWMutex
+)
+
+// Example:
+// config.OnRemoteSectionChange("database", func(new, old structure.DBConfiguration) {
+//
+// })
+// Callback is called from NotifyRemoteSectionChanges only if value by path changed or config is applied first time,
+// path is dot separated case insensitive json path.
+// If section is absent in config, zero value is passed
+func OnRemoteSectionChange[T any](path string, f func(newSection, oldSection T)) {
+	subscriber := sectionSubscriber{
+		path: path,
+		handle: func(newConfig, oldConfig interface{}) error {
+			var newSection, oldSection T
+			if err := extractSection(newConfig, path, &newSection); err != nil {
+				return err
+			}
+			if err := extractSection(oldConfig, path, &oldSection); err != nil {
+				return err
+			}
+			f(newSection, oldSection)
+			return nil
+		},
+	}
+	sectionSubscribersLock.Lock()
+	sectionSubscribers = append(sectionSubscribers, subscriber)
+	sectionSubscribersLock.Unlock()
+}
+
+// NotifyRemoteSectionChanges calls OnRemoteSectionChange callbacks for changed sections,
+// bootstrap calls it each time remote config is applied.
+// Nil oldConfig means that config is applied first time, all callbacks are called then.
+// If callback fails, already notified callbacks are called again with oldConfig, unless it is nil
+func NotifyRemoteSectionChanges(newConfig, oldConfig interface{}) error {
+	sectionSubscribersLock.RLock()
+	subscribers := sectionSubscribers
+	sectionSubscribersLock.RUnlock()
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	diff, err := DiffRemoteConfigs(oldConfig, newConfig)
+	if err != nil {
+		return err
+	}
+	notified := make([]sectionSubscriber, 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		if oldConfig != nil && !diff.Changed(subscriber.path) {
+			continue
+		}
+		if err := subscriber.call(newConfig, oldConfig); err != nil {
+			if oldConfig != nil {
+				for _, notifiedSubscriber := range notified {
+					_ = notifiedSubscriber.call(oldConfig, newConfig)
+				}
+			}
+			return err
+		}
+		notified = append(notified, subscriber)
+	}
+	return nil
+}
+
+func (s sectionSubscriber) call(newConfig, oldConfig interface{}) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("section %s: recovered panic: %v", s.path, recovered)
+		}
+	}()
+	if err := s.handle(newConfig, oldConfig); err != nil {
+		return fmt.Errorf("section %s: %v", s.path, err)
+	}
+	return nil
+}
+
+func extractSection(config interface{}, path string, sectionPtr interface{}) error {
+	value, err := toJsonValue(config)
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		for _, key := range strings.Split(path, pathSeparator) {
+			value = getJsonChild(value, key)
+			if value == nil {
+				return nil
+			}
+		}
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, sectionPtr)
+}
+
+func getJsonChild(value interface{}, key string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if child, ok := v[key]; ok {
+			return child
+		}
+		for k, child := range v {
+			if strings.EqualFold(k, key) {
+				return child
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(v) {
+			return v[i]
+		}
+	}
+	return nil
+}
+
+func toJsonValue(config interface{}) (interface{}, error) {
+	if config == nil {
+		return map[string]interface{}{}, nil
+	}
+	if rv := reflect.ValueOf(config); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return map[string]interface{}{}, nil
+	}
+	bytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(bytes, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func flattenJsonValue(path string, value interface{}, result map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && path != "" {
+			result[path] = v
+		}
+		for key, child := range v {
+			flattenJsonValue(joinPath(path, key), child, result)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			result[path] = v
+		}
+		for i, child := range v {
+			flattenJsonValue(joinPath(path, strconv.Itoa(i)), child, result)
+		}
+	default:
+		result[path] = v
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + pathSeparator + key
+}
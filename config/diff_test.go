@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffTestDatabase struct {
+	Address string
+	Port    int
+}
+
+type diffTestConfig struct {
+	Database diffTestDatabase `json:"database"`
+	Hosts    []string         `json:"hosts"`
+	Debug    bool             `json:"debug"`
+}
+
+func TestDiffRemoteConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	oldConfig := &diffTestConfig{Database: diffTestDatabase{Address: "a", Port: 1}, Hosts: []string{"h1"}}
+	newConfig := &diffTestConfig{Database: diffTestDatabase{Address: "a", Port: 2}, Hosts: []string{"h1", "h2"}}
+
+	diff, err := DiffRemoteConfigs(oldConfig, newConfig)
+	assert.NoError(err)
+	assert.Equal([]string{"database.port", "hosts.1"}, diff.Paths())
+	assert.True(diff.Changed("database"))
+	assert.True(diff.Changed("Database.port"))
+	assert.False(diff.Changed("database.address"))
+	assert.True(diff.Changed("hosts"))
+	assert.False(diff.Changed("debug"))
+
+	diff, err = DiffRemoteConfigs(nil, newConfig)
+	assert.NoError(err)
+	assert.True(diff.Changed("debug"))
+}
+
+func TestOnRemoteSectionChange(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		sectionSubscribers = nil
+	}()
+
+	type call struct {
+		newSection, oldSection diffTestDatabase
+	}
+	databaseCalls := make([]call, 0)
+	OnRemoteSectionChange("database", func(newSection, oldSection diffTestDatabase) {
+		databaseCalls = append(databaseCalls, call{newSection: newSection, oldSection: oldSection})
+	})
+	debugCalls := 0
+	OnRemoteSectionChange("debug", func(newSection, oldSection bool) {
+		debugCalls++
+	})
+
+	first := &diffTestConfig{Database: diffTestDatabase{Address: "a", Port: 1}}
+	second := &diffTestConfig{Database: diffTestDatabase{Address: "a", Port: 1}, Debug: true}
+	assert.NoError(NotifyRemoteSectionChanges(first, &diffTestConfig{}))
+	assert.NoError(NotifyRemoteSectionChanges(second, first))
+
+	assert.Equal([]call{{newSection: first.Database}}, databaseCalls)
+	assert.Equal(1, debugCalls)
+}
+
+func TestNotifyRemoteSectionChangesRollback(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		sectionSubscribers = nil
+	}()
+
+	databaseCalls := make([]diffTestDatabase, 0)
+	OnRemoteSectionChange("database", func(newSection, oldSection diffTestDatabase) {
+		databaseCalls = append(databaseCalls, newSection)
+	})
+	hostsCalls := 0
+	OnRemoteSectionChange("hosts", func(newSection, oldSection []string) {
+		hostsCalls++
+	})
+	OnRemoteSectionChange("debug", func(newSection, oldSection bool) {
+		if newSection {
+			panic("debug is not allowed")
+		}
+	})
+
+	first := &diffTestConfig{Database: diffTestDatabase{Address: "a", Port: 1}}
+	second := &diffTestConfig{Database: diffTestDatabase{Address: "b", Port: 1}, Debug: true}
+	assert.NoError(NotifyRemoteSectionChanges(first, nil))
+	assert.Error(NotifyRemoteSectionChanges(second, first))
+
+	// database is rolled back, not changed hosts section is not notified
+	assert.Equal([]diffTestDatabase{first.Database, second.Database, first.Database}, databaseCalls)
+	assert.Equal(1, hostsCalls)
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/utils"
+)
+
+var (
+	lastRemoteOverrides atomic.Value
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// EnvOverride describes remote config value replaced by RC_ISP_<path> env variable
+type EnvOverride struct {
+	// dot separated lower case json path, array elements are addressed by index
+	Path string
+	// explicit #{type} suffix or type inferred from remote config struct
+	Type PropertyType
+	// true if type was inferred from remote config struct
+	Inferred bool
+}
+
+type EnvOverrides []EnvOverride
+
+func (o EnvOverrides) Paths() []string {
+	paths := make([]string, len(o))
+	for i, override := range o {
+		paths[i] = override.Path
+	}
+	return paths
+}
+
+// RemoteConfigEnvOverrides returns overrides applied to last prepared remote config
+func RemoteConfigEnvOverrides() EnvOverrides {
+	overrides, _ := lastRemoteOverrides.Load().(EnvOverrides)
+	return overrides
+}
+
+// Overrides values in json document by env variables with envPrefix.
+// Variable name after prefix is a dot separated path, e.g. RC_ISP_DATABASE.PORT, RC_ISP_HOSTS.0,
+// value may have #{int}, #{bool}, #{float32}, #{float64}, #{string} or #{json} suffix,
+// without suffix type is inferred from configType field, unknown fields are set as strings
+func overrideConfigurationFromEnv(src []byte, envPrefix string, configType reflect.Type) ([]byte, EnvOverrides, error) {
+	envPrefix = envPrefix + "_"
+	overrides := getEnvOverrides(envPrefix)
+	if len(overrides) == 0 {
+		return src, nil, nil
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(src, &root); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal to map: %v", err)
+	}
+	if root == nil {
+		root = make(map[string]interface{})
+	}
+
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	// parents are overridden before children, so RC_ISP_DB=#{json} may be refined by RC_ISP_DB.PORT
+	sort.Strings(paths)
+
+	applied := make(EnvOverrides, 0, len(paths))
+	for _, path := range paths {
+		val := overrides[path]
+		keys := strings.Split(path, pathSeparator)
+		v, t := getValueAndType(val)
+		inferred := false
+		if t == "" {
+			t, inferred = inferPropertyType(configType, keys), true
+		}
+		newValue, err := castOverride(v, t, configType, keys)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not override remote config variable %s, new value: %v, err: %v", path, val, err)
+		}
+		root, err = setJsonValue(root, keys, newValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not override remote config variable %s: %v", path, err)
+		}
+		applied = append(applied, EnvOverride{Path: path, Type: t, Inferred: inferred})
+	}
+
+	bytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marhal to json: %v", err)
+	}
+
+	return bytes, applied, nil
+}
+
+func castOverride(v string, t PropertyType, configType reflect.Type, keys []string) (interface{}, error) {
+	if t == Int {
+		if fieldType := findPathType(configType, keys); fieldType == durationType {
+			if _, err := strconv.Atoi(v); err != nil {
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return nil, err
+				}
+				return int64(d), nil
+			}
+		}
+	}
+	return castStringTo(v, t)
+}
+
+func inferPropertyType(configType reflect.Type, keys []string) PropertyType {
+	t := findPathType(configType, keys)
+	if t == nil {
+		return String
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int
+	case reflect.Float32:
+		return Float32
+	case reflect.Float64:
+		return Float64
+	case reflect.Bool:
+		return Bool
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return Json
+	default:
+		return String
+	}
+}
+
+// returns type of value by json path in config type or nil if path is not found
+func findPathType(t reflect.Type, keys []string) reflect.Type {
+	for _, key := range keys {
+		if t == nil {
+			return nil
+		}
+		t = indirectType(t)
+		switch t.Kind() {
+		case reflect.Struct:
+			t = findFieldType(t, key)
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(key); err != nil {
+				return nil
+			}
+			t = t.Elem()
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	if t == nil {
+		return nil
+	}
+	return indirectType(t)
+}
+
+func findFieldType(t reflect.Type, key string) reflect.Type {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if fieldType := findFieldType(indirectType(field.Type), key); fieldType != nil {
+				return fieldType
+			}
+			continue
+		}
+		name, accept := utils.GetFieldName(field)
+		if accept && strings.EqualFold(name, key) {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// sets value by path, creates missing objects, existing object keys are matched case insensitive,
+// arrays are extended with nulls up to index
+func setJsonValue(node interface{}, keys []string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key := keys[0]
+	switch v := node.(type) {
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid array index '%s'", key)
+		}
+		for len(v) <= i {
+			v = append(v, nil)
+		}
+		child, err := setJsonValue(v[i], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = child
+		return v, nil
+	case map[string]interface{}:
+		existingKey := key
+		for k := range v {
+			if strings.EqualFold(k, key) {
+				existingKey = k
+				break
+			}
+		}
+		child, err := setJsonValue(v[existingKey], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[existingKey] = child
+		return v, nil
+	case nil:
+		child, err := setJsonValue(nil, keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 {
+			arr := make([]interface{}, i+1)
+			arr[i] = child
+			return arr, nil
+		}
+		return map[string]interface{}{key: child}, nil
+	default:
+		return nil, errors.New("could not set nested value in primitive value")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type overrideTestAddress struct {
+	IP   string
+	Port string
+}
+
+type overrideTestConfig struct {
+	Addresses []overrideTestAddress
+	Limits    map[string]int
+	Database  overrideTestAddress
+	Retries   int
+	Enabled   bool
+	Timeout   time.Duration
+}
+
+func TestOverrideConfigurationFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	env := map[string]string{
+		RemoteConfigEnvPrefix + "_ADDRESSES.1.PORT": "9001",
+		RemoteConfigEnvPrefix + "_DATABASE":         `{"ip":"10.0.0.1","port":"5432"}`,
+		RemoteConfigEnvPrefix + "_DATABASE.PORT":    "6432",
+		RemoteConfigEnvPrefix + "_LIMITS":           `{"a":1}#{json}`,
+		RemoteConfigEnvPrefix + "_RETRIES":          "3",
+		RemoteConfigEnvPrefix + "_ENABLED":          "true",
+		RemoteConfigEnvPrefix + "_TIMEOUT":          "2s",
+	}
+	for name, value := range env {
+		assert.NoError(os.Setenv(name, value))
+		defer os.Unsetenv(name)
+	}
+
+	original := overrideTestConfig{
+		Addresses: []overrideTestAddress{{IP: "127.0.0.1", Port: "9000"}},
+		Retries:   1,
+	}
+	bytes, err := json.Marshal(original)
+	assert.NoError(err)
+
+	result, overrides, err := overrideConfigurationFromEnv(bytes, RemoteConfigEnvPrefix, reflect.TypeOf(&original))
+	assert.NoError(err)
+	cfg := overrideTestConfig{}
+	assert.NoError(json.Unmarshal(result, &cfg))
+	assert.Equal(overrideTestConfig{
+		Addresses: []overrideTestAddress{{IP: "127.0.0.1", Port: "9000"}, {Port: "9001"}},
+		Limits:    map[string]int{"a": 1},
+		Database:  overrideTestAddress{IP: "10.0.0.1", Port: "6432"},
+		Retries:   3,
+		Enabled:   true,
+		Timeout:   2 * time.Second,
+	}, cfg)
+
+	types := make(map[string]PropertyType)
+	for _, override := range overrides {
+		types[override.Path] = override.Type
+	}
+	assert.Equal(map[string]PropertyType{
+		"addresses.1.port": String,
+		"database":         Json,
+		"database.port":    String,
+		"limits":           Json,
+		"retries":          Int,
+		"enabled":          Bool,
+		"timeout":          Int,
+	}, filterTestOverrides(types, env))
+
+	assert.NoError(os.Setenv(RemoteConfigEnvPrefix+"_RETRIES", "many"))
+	_, _, err = overrideConfigurationFromEnv(bytes, RemoteConfigEnvPrefix, reflect.TypeOf(&original))
+	assert.Error(err)
+}
+
+// env may contain overrides leaked from other tests
+func filterTestOverrides(types map[string]PropertyType, env map[string]string) map[string]PropertyType {
+	result := make(map[string]PropertyType)
+	for name := range env {
+		path := strings.ToLower(name[len(RemoteConfigEnvPrefix)+1:])
+		result[path] = types[path]
+	}
+	return result
+}
@@ -35,3 +35,29 @@ func (c *customSchema) getGeneratorByName(name string) generator {
 
 	return c.mapSchema[name]
 }
+
+// OneOf returns generator which describes interface field as one of variants schemas, e.g.
+// schema.CustomGenerators.Register("storage", schema.OneOf(FileStorage{}, S3Storage{}))
+// and field tagged with `schemaGen:"storage"`
+func OneOf(variants ...interface{}) generator {
+	types := make([]*jsonschema.Type, 0, len(variants))
+	for _, variant := range variants {
+		t := reflect.TypeOf(variant)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		var variantType *jsonschema.Type
+		if t.Kind() == reflect.Struct {
+			s := DereferenceSchema(GenerateConfigSchema(variant))
+			variantType = s.Type
+		} else {
+			variantType = jsonschema.ReflectFromType(t).Type
+		}
+		variantType.Version = ""
+		variantType.Title = t.Name()
+		types = append(types, variantType)
+	}
+	return func(field reflect.StructField, t *jsonschema.Type) {
+		t.OneOf = types
+	}
+}
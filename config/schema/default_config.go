@@ -2,8 +2,14 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	p "path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/integration-system/isp-lib/v2/utils"
 )
@@ -38,3 +44,178 @@ func ResolveDefaultConfigPath(path string) string {
 		return p.Join(dir, path)
 	}
 }
+
+// GenerateDefaultConfig creates remote config of cfgPtr type with values from `default` tags,
+// result uses the same field names as schema
+func GenerateDefaultConfig(cfgPtr interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(cfgPtr)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	cfg := reflect.New(t)
+	if err := SetDefaults(cfg.Interface()); err != nil {
+		return nil, err
+	}
+	bytes, err := utils.ConvertGoToBytes(cfg.Interface())
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]interface{})
+	if err := json.Unmarshal(bytes, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// SetDefaults sets values from `default` tags to zero fields of struct pointed by cfgPtr, nested structs are filled recursively.
+// Slices, maps and structs defaults are json values, slices also may be comma separated list of elements
+func SetDefaults(cfgPtr interface{}) error {
+	v := reflect.ValueOf(cfgPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("expecting not nil pointer to struct")
+	}
+	return setStructDefaults(v.Elem(), "")
+}
+
+func setStructDefaults(v reflect.Value, path string) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldValue := v.Field(i)
+		fieldPath := path + field.Name
+		if defaultValue, ok := field.Tag.Lookup(tagDefault); ok && fieldValue.IsZero() {
+			if err := parseDefault(fieldValue, defaultValue); err != nil {
+				return fmt.Errorf("field %s: invalid default value '%s': %v", fieldPath, defaultValue, err)
+			}
+			continue
+		}
+		if err := setNestedDefaults(fieldValue, fieldPath+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setNestedDefaults(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return setStructDefaults(v, path)
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		if !v.IsNil() {
+			return setStructDefaults(v.Elem(), path)
+		}
+		// nil pointer is set only if nested struct has defaults
+		elem := reflect.New(v.Type().Elem())
+		if err := setStructDefaults(elem.Elem(), path); err != nil {
+			return err
+		}
+		if !elem.Elem().IsZero() {
+			v.Set(elem)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := setNestedDefaults(v.Index(i), path+strconv.Itoa(i)+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseDefault(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			n, intErr := strconv.ParseInt(value, 10, 64)
+			if intErr != nil {
+				return err
+			}
+			d = time.Duration(n)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := parseDefault(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		trimmed := strings.TrimSpace(value)
+		if strings.HasPrefix(trimmed, "[") {
+			return utils.ConvertBytesToGo([]byte(trimmed), v.Addr().Interface())
+		}
+		parts := strings.Split(value, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := parseDefault(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Interface:
+		var val interface{}
+		if err := json.Unmarshal([]byte(value), &val); err != nil {
+			val = value
+		}
+		v.Set(reflect.ValueOf(val))
+	default:
+		return utils.ConvertBytesToGo([]byte(value), v.Addr().Interface())
+	}
+	return nil
+}
+
+// json representation of default tag value for field type
+func defaultJsonValue(fieldType reflect.Type, value string) (interface{}, error) {
+	v := reflect.New(fieldType)
+	if err := parseDefault(v.Elem(), value); err != nil {
+		return nil, err
+	}
+	bytes, err := utils.ConvertGoToBytes(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultsDatabase struct {
+	Address string `default:"localhost"`
+	Port    int    `default:"5432"`
+}
+
+type defaultsConfig struct {
+	Database   defaultsDatabase
+	Replica    *defaultsDatabase
+	Optional   *struct{ Value string }
+	Enabled    bool           `default:"true"`
+	Timeout    time.Duration  `default:"1m30s"`
+	Ratio      float64        `default:"0.5"`
+	Hosts      []string       `default:"a, b"`
+	Ports      []int          `default:"[1, 2]"`
+	Limits     map[string]int `default:"{\"a\": 1}"`
+	Retries    *int           `default:"3"`
+	Overridden string         `default:"default"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &defaultsConfig{Overridden: "value"}
+	assert.NoError(SetDefaults(cfg))
+	retries := 3
+	assert.Equal(&defaultsConfig{
+		Database:   defaultsDatabase{Address: "localhost", Port: 5432},
+		Replica:    &defaultsDatabase{Address: "localhost", Port: 5432},
+		Enabled:    true,
+		Timeout:    90 * time.Second,
+		Ratio:      0.5,
+		Hosts:      []string{"a", "b"},
+		Ports:      []int{1, 2},
+		Limits:     map[string]int{"a": 1},
+		Retries:    &retries,
+		Overridden: "value",
+	}, cfg)
+
+	type invalidConfig struct {
+		Port int `default:"port"`
+	}
+	assert.Error(SetDefaults(&invalidConfig{}))
+}
+
+func TestGenerateDefaultConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, err := GenerateDefaultConfig(&defaultsConfig{})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"address": "localhost", "port": float64(5432)}, cfg["database"])
+	assert.Equal(float64(90*time.Second), cfg["timeout"])
+	assert.Equal([]interface{}{"a", "b"}, cfg["hosts"])
+	assert.Equal("default", cfg["overridden"])
+
+	s := GenerateConfigSchema(&defaultsConfig{})
+	assert.Equal(true, s.Properties["enabled"].Default)
+	assert.Equal([]interface{}{float64(1), float64(2)}, s.Properties["ports"].Default)
+}
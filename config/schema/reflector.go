@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/integration-system/jsonschema"
+)
+
+// definition name which jsonschema gives to any anonymous struct, so all of them collide
+const anonymousDefinitionName = "."
+
+// Extensions holds schema keywords not supported by jsonschema.Type (examples, deprecated, readOnly, secret)
+// and zero minimum and maximum which are omitted by it, they are merged into json representation of the type
+type Extensions map[*jsonschema.Type]map[string]interface{}
+
+func (e Extensions) set(t *jsonschema.Type, keyword string, value interface{}) {
+	keywords, ok := e[t]
+	if !ok {
+		keywords = make(map[string]interface{})
+		e[t] = keywords
+	}
+	keywords[keyword] = value
+}
+
+type schemaGenerator struct {
+	definitions    jsonschema.Definitions
+	anonymousNames map[reflect.Type]string
+	extensions     Extensions
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		definitions:    make(jsonschema.Definitions),
+		anonymousNames: make(map[reflect.Type]string),
+		extensions:     make(Extensions),
+	}
+}
+
+func (g *schemaGenerator) reflector() *jsonschema.Reflector {
+	return &jsonschema.Reflector{
+		FieldNameReflector: GetNameAndRequiredFlag,
+		FieldReflector:     g.reflectField,
+		ExpandedStruct:     true,
+	}
+}
+
+func (g *schemaGenerator) generate(cfgPtr interface{}) Schema {
+	t := reflect.TypeOf(cfgPtr)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// root type is passed without pointer, otherwise it shares definition name with anonymous structs
+	s := g.reflector().ReflectFromType(t)
+	delete(s.Definitions, anonymousDefinitionName)
+	for name, def := range g.definitions {
+		s.Definitions[name] = def
+	}
+	return s
+}
+
+func (g *schemaGenerator) reflectField(field reflect.StructField, t *jsonschema.Type) {
+	g.fixAnonymousStructRef(field, t)
+	SetProperties(field, t)
+	g.setExtensions(field, t)
+}
+
+// jsonschema names definitions of all unnamed types (anonymous structs, slices, maps) equally,
+// so after first anonymous struct all of them reference its definition, such types are reflected again
+func (g *schemaGenerator) fixAnonymousStructRef(field reflect.StructField, t *jsonschema.Type) {
+	if hasAnonymousRef(t) {
+		*t = *g.reflectType(field, field.Type)
+	}
+}
+
+func hasAnonymousRef(t *jsonschema.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Ref == "#/definitions/"+anonymousDefinitionName {
+		return true
+	}
+	return hasAnonymousRef(t.Items) || hasAnonymousRef(t.PatternProperties[".*"])
+}
+
+func (g *schemaGenerator) reflectType(field reflect.StructField, t reflect.Type) *jsonschema.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Struct:
+			return &jsonschema.Type{Ref: "#/definitions/" + g.anonymousDefinition(field, t)}
+		case reflect.Slice, reflect.Array:
+			if t.Elem().Kind() != reflect.Uint8 {
+				result := &jsonschema.Type{Type: "array", Items: g.reflectType(field, t.Elem())}
+				if t.Kind() == reflect.Array {
+					result.MinItems = t.Len()
+					result.MaxItems = t.Len()
+				}
+				return result
+			}
+		case reflect.Map:
+			return &jsonschema.Type{
+				Type:              "object",
+				PatternProperties: map[string]*jsonschema.Type{".*": g.reflectType(field, t.Elem())},
+			}
+		}
+	}
+
+	r := g.reflector()
+	r.ExpandedStruct = false
+	s := r.ReflectFromType(t)
+	g.addDefinitions(s.Definitions)
+	s.Type.Version = ""
+	return s.Type
+}
+
+func (g *schemaGenerator) addDefinitions(definitions jsonschema.Definitions) {
+	for name, def := range definitions {
+		if name != anonymousDefinitionName {
+			g.definitions[name] = def
+		}
+	}
+}
+
+func (g *schemaGenerator) anonymousDefinition(field reflect.StructField, t reflect.Type) string {
+	if name, ok := g.anonymousNames[t]; ok {
+		return name
+	}
+	name := "anonymous" + field.Name
+	for i := 1; g.definitions[name] != nil; i++ {
+		name = "anonymous" + field.Name + strconv.Itoa(i)
+	}
+	// reserve name before reflection, so nested anonymous structs get different names
+	g.anonymousNames[t] = name
+	g.definitions[name] = &jsonschema.Type{}
+
+	s := g.reflector().ReflectFromType(t)
+	g.addDefinitions(s.Definitions)
+	s.Type.Version = ""
+	g.definitions[name] = s.Type
+	return name
+}
+
+func (g *schemaGenerator) setExtensions(field reflect.StructField, t *jsonschema.Type) {
+	if example, ok := field.Tag.Lookup(tagExample); ok {
+		g.extensions.set(t, "examples", []interface{}{parseTagValue(example, t)})
+	}
+	if deprecated, ok := field.Tag.Lookup(tagDeprecated); ok && isTrue(deprecated) {
+		g.extensions.set(t, "deprecated", true)
+	}
+	if readOnly, ok := field.Tag.Lookup(tagReadOnly); ok && isTrue(readOnly) {
+		g.extensions.set(t, "readOnly", true)
+	}
+	if secret, ok := field.Tag.Lookup(tagSecret); ok && isTrue(secret) {
+		g.extensions.set(t, "secret", true)
+	}
+	if args := getValidatorsMap(field)["range"]; len(args) > 0 {
+		if val, err := strconv.Atoi(args[0]); err == nil && val == 0 {
+			g.extensions.set(t, "minimum", 0)
+		}
+		if len(args) > 1 {
+			if val, err := strconv.Atoi(args[1]); err == nil && val == 0 {
+				g.extensions.set(t, "maximum", 0)
+			}
+		}
+	}
+}
+
+func marshalSchema(s Schema, extensions Extensions) (interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	root, err := marshalType(s.Type, extensions)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Definitions) > 0 {
+		definitions, err := marshalTypesMap(s.Definitions, extensions)
+		if err != nil {
+			return nil, err
+		}
+		root["definitions"] = definitions
+	}
+	return root, nil
+}
+
+func marshalType(t *jsonschema.Type, extensions Extensions) (map[string]interface{}, error) {
+	if t == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	for keyword, value := range extensions[t] {
+		result[keyword] = value
+	}
+
+	for keyword, child := range map[string]*jsonschema.Type{
+		"items":           t.Items,
+		"additionalItems": t.AdditionalItems,
+		"not":             t.Not,
+		"media":           t.Media,
+	} {
+		if child == nil {
+			continue
+		}
+		if result[keyword], err = marshalType(child, extensions); err != nil {
+			return nil, err
+		}
+	}
+	for keyword, children := range map[string]map[string]*jsonschema.Type{
+		"properties":        t.Properties,
+		"patternProperties": t.PatternProperties,
+		"dependencies":      t.Dependencies,
+		"definitions":       t.Definitions,
+	} {
+		if len(children) == 0 {
+			continue
+		}
+		if result[keyword], err = marshalTypesMap(children, extensions); err != nil {
+			return nil, err
+		}
+	}
+	for keyword, children := range map[string][]*jsonschema.Type{
+		"allOf": t.AllOf,
+		"anyOf": t.AnyOf,
+		"oneOf": t.OneOf,
+	} {
+		if len(children) == 0 {
+			continue
+		}
+		values := make([]interface{}, len(children))
+		for i, child := range children {
+			if values[i], err = marshalType(child, extensions); err != nil {
+				return nil, err
+			}
+		}
+		result[keyword] = values
+	}
+	return result, nil
+}
+
+func marshalTypesMap(types map[string]*jsonschema.Type, extensions Extensions) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(types))
+	for key, t := range types {
+		value, err := marshalType(t, extensions)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
@@ -1,9 +1,11 @@
 package schema
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/integration-system/jsonschema"
 	"github.com/mohae/deepcopy"
-	"strings"
 )
 
 type Schema *jsonschema.Schema
@@ -12,15 +14,43 @@ type ConfigSchema struct {
 	Version       string                 `json:"version"`
 	Schema        Schema                 `json:"schema"`
 	DefaultConfig map[string]interface{} `json:"config"`
+
+	extensions Extensions
 }
 
-func GenerateConfigSchema(cfgPtr interface{}) Schema {
-	ref := jsonschema.Reflector{
-		FieldNameReflector: GetNameAndRequiredFlag,
-		FieldReflector:     SetProperties,
-		ExpandedStruct:     true,
+// NewConfigSchema generates schema with keywords which are not supported by jsonschema.Type
+// (examples, deprecated, readOnly, secret), they are included on marshaling to json
+func NewConfigSchema(version string, cfgPtr interface{}) ConfigSchema {
+	g := newSchemaGenerator()
+	return ConfigSchema{
+		Version:    version,
+		Schema:     generateConfigSchema(g, cfgPtr),
+		extensions: g.extensions,
 	}
-	s := ref.Reflect(cfgPtr)
+}
+
+func (c ConfigSchema) MarshalJSON() ([]byte, error) {
+	s, err := marshalSchema(c.Schema, c.extensions)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Version       string                 `json:"version"`
+		Schema        interface{}            `json:"schema"`
+		DefaultConfig map[string]interface{} `json:"config"`
+	}{
+		Version:       c.Version,
+		Schema:        s,
+		DefaultConfig: c.DefaultConfig,
+	})
+}
+
+func GenerateConfigSchema(cfgPtr interface{}) Schema {
+	return generateConfigSchema(newSchemaGenerator(), cfgPtr)
+}
+
+func generateConfigSchema(g *schemaGenerator, cfgPtr interface{}) Schema {
+	s := g.generate(cfgPtr)
 	s.Title = "Remote config"
 	s.Version = ""
 	return s
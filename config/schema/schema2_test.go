@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/integration-system/isp-lib/v2/internal/testdata/testone"
 	"github.com/integration-system/isp-lib/v2/internal/testdata/testtwo"
@@ -106,8 +107,6 @@ func TestDefinitionCrossPackageCollision(t *testing.T) {
 }
 
 func TestGenerateConfigSchemaAnonymousStruct(t *testing.T) {
-	// TODO
-	t.Skip("known bug")
 	type mockRemoteConfig struct {
 		Value1 string
 		Value2 int
@@ -122,3 +121,79 @@ func TestGenerateConfigSchemaAnonymousStruct(t *testing.T) {
 	value3Ref := strings.TrimPrefix(s.Properties["value3"].Ref, "#/definitions/")
 	assert.Contains(t, s.Definitions, value3Ref)
 }
+
+func TestGenerateConfigSchemaDifferentAnonymousStructs(t *testing.T) {
+	assert := assert.New(t)
+	type mockRemoteConfig struct {
+		First struct {
+			A string
+		}
+		Second []struct {
+			B int
+		}
+		Tags []string
+	}
+
+	s := GenerateConfigSchema(&mockRemoteConfig{})
+	assert.Len(s.Definitions, 2)
+	first := s.Definitions[strings.TrimPrefix(s.Properties["first"].Ref, "#/definitions/")]
+	second := s.Definitions[strings.TrimPrefix(s.Properties["second"].Items.Ref, "#/definitions/")]
+	if assert.NotNil(first) && assert.NotNil(second) {
+		assert.Contains(first.Properties, "a")
+		assert.Contains(second.Properties, "b")
+	}
+	assert.Equal("array", s.Properties["tags"].Type)
+	assert.Equal("string", s.Properties["tags"].Items.Type)
+}
+
+type oneOfFile struct {
+	Path string
+}
+
+type oneOfS3 struct {
+	Bucket string
+}
+
+func TestConfigSchemaKeywords(t *testing.T) {
+	assert := assert.New(t)
+	CustomGenerators.Register("testStorage", OneOf(oneOfFile{}, oneOfS3{}))
+	defer CustomGenerators.Remove("testStorage")
+
+	type mockRemoteConfig struct {
+		Level    string        `enum:"debug|info|error" example:"info"`
+		Codes    []int         `enum:"1|2" minItems:"1" maxItems:"2"`
+		Timeout  time.Duration `deprecated:"true"`
+		Version  string        `readOnly:"true"`
+		Password string        `secret:"true"`
+		Storage  interface{}   `schemaGen:"testStorage"`
+	}
+
+	cs := NewConfigSchema("1.0.0", &mockRemoteConfig{})
+	props := cs.Schema.Properties
+	assert.Equal([]interface{}{"debug", "info", "error"}, props["level"].Enum)
+	assert.Equal([]interface{}{int64(1), int64(2)}, props["codes"].Items.Enum)
+	assert.Equal(1, props["codes"].MinItems)
+	assert.Equal(2, props["codes"].MaxItems)
+	assert.Equal("string", props["timeout"].Type)
+	assert.Equal("duration", props["timeout"].Format)
+	assert.Equal("password", props["password"].Format)
+	if assert.Len(props["storage"].OneOf, 2) {
+		assert.Equal("oneOfFile", props["storage"].OneOf[0].Title)
+		assert.Contains(props["storage"].OneOf[1].Properties, "bucket")
+	}
+
+	bytes, err := json.Marshal(cs)
+	assert.NoError(err)
+	var result struct {
+		Version string
+		Schema  struct {
+			Properties map[string]map[string]interface{}
+		}
+	}
+	assert.NoError(json.Unmarshal(bytes, &result))
+	assert.Equal("1.0.0", result.Version)
+	assert.Equal([]interface{}{"info"}, result.Schema.Properties["level"]["examples"])
+	assert.Equal(true, result.Schema.Properties["timeout"]["deprecated"])
+	assert.Equal(true, result.Schema.Properties["version"]["readOnly"])
+	assert.Equal(true, result.Schema.Properties["password"]["secret"])
+}
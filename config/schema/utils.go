@@ -1,9 +1,11 @@
 package schema
 
 import (
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/asaskevich/govalidator"
@@ -15,8 +17,22 @@ const (
 	tagDefault      = "default"
 	tagSchema       = "schema"
 	tagCustomSchema = "schemaGen"
+	// values separated by |, e.g. `enum:"debug|info|error"`
+	tagEnum       = "enum"
+	tagMinItems   = "minItems"
+	tagMaxItems   = "maxItems"
+	tagExample    = "example"
+	tagDeprecated = "deprecated"
+	tagReadOnly   = "readOnly"
+	// same tag is used to mask config values in logs
+	tagSecret = "secret"
+
+	formatDuration = "duration"
+	formatPassword = "password"
 )
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
 func GetNameAndRequiredFlag(field reflect.StructField) (string, bool) {
 	if field.PkgPath != "" { // unexported field, ignore it
 		return "", false
@@ -50,10 +66,15 @@ func SetProperties(field reflect.StructField, t *jsonschema.Type) {
 	}
 
 	if defaultValue, ok := field.Tag.Lookup(tagDefault); ok {
-		t.Default = defaultValue
+		if value, err := defaultJsonValue(field.Type, defaultValue); err == nil {
+			t.Default = value
+		} else {
+			t.Default = defaultValue
+		}
 	}
 
 	setValidators(field, t)
+	setTypeKeywords(field, t)
 
 	if customValue, ok := field.Tag.Lookup(tagCustomSchema); ok {
 		if f := CustomGenerators.getGeneratorByName(customValue); f != nil {
@@ -119,6 +140,71 @@ func setValidators(field reflect.StructField, t *jsonschema.Type) {
 	}
 }
 
+func setTypeKeywords(field reflect.StructField, t *jsonschema.Type) {
+	fieldType := field.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == durationType {
+		// duration string like "1m30s", integer nanoseconds are accepted too
+		t.Type = "string"
+		t.Format = formatDuration
+	}
+
+	if enum, ok := field.Tag.Lookup(tagEnum); ok && enum != "" {
+		enumType := t
+		if t.Type == "array" && t.Items != nil {
+			enumType = t.Items
+		}
+		enumType.Enum = nil
+		for _, val := range strings.Split(enum, "|") {
+			enumType.Enum = append(enumType.Enum, parseTagValue(val, enumType))
+		}
+	}
+	if minItems, ok := field.Tag.Lookup(tagMinItems); ok {
+		if val, err := strconv.Atoi(minItems); err == nil {
+			t.MinItems = val
+		}
+	}
+	if maxItems, ok := field.Tag.Lookup(tagMaxItems); ok {
+		if val, err := strconv.Atoi(maxItems); err == nil {
+			t.MaxItems = val
+		}
+	}
+	if secret, ok := field.Tag.Lookup(tagSecret); ok && isTrue(secret) && t.Type == "string" {
+		t.Format = formatPassword
+	}
+}
+
+// converts tag value to json type of schema, returns string if value could not be converted
+func parseTagValue(value string, t *jsonschema.Type) interface{} {
+	switch t.Type {
+	case "integer":
+		if val, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return val
+		}
+	case "number":
+		if val, err := strconv.ParseFloat(value, 64); err == nil {
+			return val
+		}
+	case "boolean":
+		if val, err := strconv.ParseBool(value); err == nil {
+			return val
+		}
+	case "object", "array", "":
+		var val interface{}
+		if err := json.Unmarshal([]byte(value), &val); err == nil {
+			return val
+		}
+	}
+	return value
+}
+
+func isTrue(value string) bool {
+	val, err := strconv.ParseBool(value)
+	return err == nil && val
+}
+
 func getValidatorsMap(field reflect.StructField) map[string][]string {
 	value, ok := field.Tag.Lookup("valid")
 	if !ok {
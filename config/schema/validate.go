@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/integration-system/jsonschema"
+)
+
+// Violation of schema by value at Path, Path is dot separated json path, array elements are addressed by index,
+// empty path means document root
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by ValidateJson and contains all found violations sorted by path
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		path := v.Path
+		if path == "" {
+			path = "(root)"
+		}
+		messages[i] = fmt.Sprintf("%s: %s", path, v.Message)
+	}
+	return "schema violations: " + strings.Join(messages, "; ")
+}
+
+// ValidateJson checks json document against schema generated by GenerateConfigSchema.
+// Supported keywords: type, properties, additionalProperties, patternProperties, required, items,
+// minItems, maxItems, enum, minLength, maxLength, pattern, minimum, maximum, oneOf, $ref and duration format.
+// Property names are matched case insensitive and null is accepted for any type the same way as json unmarshalling does.
+// Zero minimum and maximum are not present in Schema, use ConfigSchema.Validate to check them
+func ValidateJson(s Schema, data []byte) error {
+	return validateJson(s, nil, data)
+}
+
+// Validate checks json document against schema like ValidateJson including zero minimum and maximum
+func (c ConfigSchema) Validate(data []byte) error {
+	return validateJson(c.Schema, c.extensions, data)
+}
+
+func validateJson(s Schema, extensions Extensions, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Violations: []Violation{{Message: fmt.Sprintf("invalid json: %v", err)}}}
+	}
+	v := &validator{definitions: s.Definitions, extensions: extensions}
+	v.validate("", value, s.Type)
+	if len(v.violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Path < v.violations[j].Path
+	})
+	return &ValidationError{Violations: v.violations}
+}
+
+type validator struct {
+	definitions jsonschema.Definitions
+	extensions  Extensions
+	violations  []Violation
+}
+
+func (v *validator) addf(path string, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) resolve(t *jsonschema.Type) *jsonschema.Type {
+	for depth := 0; t != nil && t.Ref != "" && depth < 32; depth++ {
+		def, ok := v.definitions[strings.TrimPrefix(t.Ref, "#/definitions/")]
+		if !ok {
+			return nil
+		}
+		t = def
+	}
+	return t
+}
+
+func (v *validator) validate(path string, value interface{}, t *jsonschema.Type) {
+	t = v.resolve(t)
+	if t == nil || value == nil {
+		return
+	}
+
+	if len(t.OneOf) > 0 {
+		matched := 0
+		for _, variant := range t.OneOf {
+			sub := &validator{definitions: v.definitions, extensions: v.extensions}
+			sub.validate(path, value, variant)
+			if len(sub.violations) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.addf(path, "must match exactly one schema from oneOf, matched %d", matched)
+		}
+	}
+
+	if t.Format == formatDuration {
+		v.validateDuration(path, value)
+		return
+	}
+	if t.Type != "" && !matchType(value, t.Type) {
+		v.addf(path, "expected %s, got %s", t.Type, jsonTypeName(value))
+		return
+	}
+	if len(t.Enum) > 0 && !inEnum(value, t.Enum) {
+		v.addf(path, "must be one of %v", t.Enum)
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(path, val, t)
+	case []interface{}:
+		if t.MinItems > 0 && len(val) < t.MinItems {
+			v.addf(path, "must contain at least %d items", t.MinItems)
+		}
+		if t.MaxItems > 0 && len(val) > t.MaxItems {
+			v.addf(path, "must contain at most %d items", t.MaxItems)
+		}
+		if t.Items != nil {
+			for i, item := range val {
+				v.validate(joinPath(path, strconv.Itoa(i)), item, t.Items)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(val)
+		if t.MinLength > 0 && length < t.MinLength {
+			v.addf(path, "length must be at least %d", t.MinLength)
+		}
+		if t.MaxLength > 0 && length > t.MaxLength {
+			v.addf(path, "length must be at most %d", t.MaxLength)
+		}
+		if t.Pattern != "" {
+			if re, err := regexp.Compile(t.Pattern); err == nil && !re.MatchString(val) {
+				v.addf(path, "must match pattern %s", t.Pattern)
+			}
+		}
+	case float64:
+		if v.hasBound(t, "minimum", t.Minimum) && val < float64(t.Minimum) {
+			v.addf(path, "must be greater than or equal to %d", t.Minimum)
+		}
+		if v.hasBound(t, "maximum", t.Maximum) && val > float64(t.Maximum) {
+			v.addf(path, "must be less than or equal to %d", t.Maximum)
+		}
+	}
+}
+
+// duration is string like "1m30s" or integer nanoseconds the same way as remote config decoding accepts it
+func (v *validator) validateDuration(path string, value interface{}) {
+	switch val := value.(type) {
+	case string:
+		if _, err := time.ParseDuration(val); err != nil {
+			v.addf(path, "invalid duration: %v", err)
+		}
+	case float64:
+		if val != math.Trunc(val) {
+			v.addf(path, "expected duration, got number")
+		}
+	default:
+		v.addf(path, "expected duration, got %s", jsonTypeName(value))
+	}
+}
+
+// zero bound is omitted by jsonschema.Type, so it is present only if it is in extensions
+func (v *validator) hasBound(t *jsonschema.Type, keyword string, value int) bool {
+	if value != 0 {
+		return true
+	}
+	_, ok := v.extensions[t][keyword]
+	return ok
+}
+
+func (v *validator) validateObject(path string, value map[string]interface{}, t *jsonschema.Type) {
+	for _, name := range t.Required {
+		if _, ok := lookupProperty(value, name); !ok {
+			v.addf(joinPath(path, name), "required property is missing")
+		}
+	}
+
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	allowAdditional := string(t.AdditionalProperties) != "false"
+	for _, key := range keys {
+		propPath := joinPath(path, key)
+		if prop := lookupSchemaProperty(t.Properties, key); prop != nil {
+			v.validate(propPath, value[key], prop)
+			continue
+		}
+		if prop := matchPatternProperty(t.PatternProperties, key); prop != nil {
+			v.validate(propPath, value[key], prop)
+			continue
+		}
+		if !allowAdditional && len(t.PatternProperties) == 0 {
+			v.addf(propPath, "unknown property")
+		}
+	}
+}
+
+func lookupProperty(value map[string]interface{}, name string) (interface{}, bool) {
+	if val, ok := value[name]; ok {
+		return val, true
+	}
+	for key, val := range value {
+		if strings.EqualFold(key, name) {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+func lookupSchemaProperty(properties map[string]*jsonschema.Type, key string) *jsonschema.Type {
+	if prop, ok := properties[key]; ok {
+		return prop
+	}
+	for name, prop := range properties {
+		if strings.EqualFold(name, key) {
+			return prop
+		}
+	}
+	return nil
+}
+
+func matchPatternProperty(patternProperties map[string]*jsonschema.Type, key string) *jsonschema.Type {
+	for pattern, prop := range patternProperties {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+			return prop
+		}
+	}
+	return nil
+}
+
+func matchType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	default:
+		return true
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(value, e) || fmt.Sprint(value) == fmt.Sprint(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
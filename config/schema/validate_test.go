@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJson(t *testing.T) {
+	assert := assert.New(t)
+	type address struct {
+		Host string `valid:"required"`
+		Port int    `valid:"range(1|65535)"`
+	}
+	type mockRemoteConfig struct {
+		Level     string    `enum:"debug|info"`
+		Addresses []address `minItems:"1"`
+		Limits    map[string]int
+		Any       interface{}
+	}
+	s := GenerateConfigSchema(&mockRemoteConfig{})
+
+	err := ValidateJson(s, []byte(`{"level":"info","addresses":[{"host":"127.0.0.1","port":80}],"limits":{"a":1},"any":[1]}`))
+	assert.NoError(err)
+	// property names are case insensitive as in unmarshalling, null is accepted
+	err = ValidateJson(s, []byte(`{"Level":"debug","ADDRESSES":[{"Host":"127.0.0.1"}],"limits":null}`))
+	assert.NoError(err)
+
+	err = ValidateJson(s, []byte(`{"level":"trace","addresses":[{"port":"80"},{"host":"h","port":70000}],"limits":{"a":1.5},"unknown":true}`))
+	validationErr := &ValidationError{}
+	if assert.True(errors.As(err, &validationErr)) {
+		assert.Equal([]Violation{
+			{Path: "addresses.0.host", Message: "required property is missing"},
+			{Path: "addresses.0.port", Message: "expected integer, got string"},
+			{Path: "addresses.1.port", Message: "must be less than or equal to 65535"},
+			{Path: "level", Message: "must be one of [debug info]"},
+			{Path: "limits.a", Message: "expected integer, got number"},
+			{Path: "unknown", Message: "unknown property"},
+		}, validationErr.Violations)
+	}
+
+	err = ValidateJson(s, []byte(`{"addresses":[]}`))
+	assert.EqualError(err, "schema violations: addresses: must contain at least 1 items")
+}
+
+func TestConfigSchema_ValidateZeroMinimum(t *testing.T) {
+	assert := assert.New(t)
+	type mockRemoteConfig struct {
+		Counter int `valid:"range(0|10)"`
+	}
+	s := NewConfigSchema("1.0.0", &mockRemoteConfig{})
+
+	assert.NoError(s.Validate([]byte(`{"counter":0}`)))
+	assert.EqualError(s.Validate([]byte(`{"counter":-1}`)), "schema violations: counter: must be greater than or equal to 0")
+
+	// zero minimum is present in json schema
+	bytes, err := json.Marshal(s)
+	assert.NoError(err)
+	assert.Contains(string(bytes), `"minimum":0`)
+}
+
+func TestValidateJsonDuration(t *testing.T) {
+	assert := assert.New(t)
+	type mockRemoteConfig struct {
+		Timeout time.Duration
+	}
+	s := GenerateConfigSchema(&mockRemoteConfig{})
+
+	assert.NoError(ValidateJson(s, []byte(`{"timeout":"1m30s"}`)))
+	// integer nanoseconds are accepted too
+	assert.NoError(ValidateJson(s, []byte(`{"timeout":1000000000}`)))
+	assert.EqualError(ValidateJson(s, []byte(`{"timeout":"1 minute"}`)),
+		`schema violations: timeout: invalid duration: time: unknown unit " minute" in duration "1 minute"`)
+	assert.EqualError(ValidateJson(s, []byte(`{"timeout":true}`)), "schema violations: timeout: expected duration, got boolean")
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// mark struct field with `secret:"true"` tag to mask its value in logs
+	tagSecret         = "secret"
+	maskedSecretValue = "******"
+
+	FileSecretProvider = "file"
+	EnvSecretProvider  = "env"
+)
+
+var (
+	// ${secret:<provider>:<reference>}
+	secretRefRegexp = regexp.MustCompile(`\$\{secret:([\w-]+):([^}]*)\}`)
+	secretRefPrefix = []byte("${secret:")
+
+	secretProviders = map[string]SecretProvider{
+		FileSecretProvider: SecretProviderFunc(readFileSecret),
+		EnvSecretProvider:  SecretProviderFunc(readEnvSecret),
+	}
+	secretProvidersLock sync.RWMutex
+)
+
+// SecretProvider resolves secret value by provider specific reference (file path, env name, vault key, etc.)
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+type SecretProviderFunc func(ref string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// RegisterSecretProvider adds or replaces provider used for ${secret:<name>:<reference>} placeholders
+func RegisterSecretProvider(name string, provider SecretProvider) {
+	secretProvidersLock.Lock()
+	secretProviders[name] = provider
+	secretProvidersLock.Unlock()
+}
+
+// ResolveSecrets replaces secret placeholders in all string values of json document
+func ResolveSecrets(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, secretRefPrefix) {
+		return data, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+	value, err := resolveSecretsInValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// MaskSecrets returns json representation of config where values of fields tagged as secret are masked
+func MaskSecrets(cfg interface{}) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Sprintf("<could not marshal config: %v>", err)
+	}
+	return maskRawSecrets(data, reflect.TypeOf(cfg))
+}
+
+// masks secret fields in json document of config type
+func maskRawSecrets(data []byte, configType reflect.Type) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Sprintf("<could not unmarshal config: %v>", err)
+	}
+	maskSecretFields(value, configType)
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("<could not marshal config: %v>", err)
+	}
+	return string(masked)
+}
+
+func maskSecretFields(value interface{}, t reflect.Type) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous {
+				maskSecretFields(m, field.Type)
+				continue
+			}
+			name, accept := utils.GetFieldName(field)
+			if !accept {
+				continue
+			}
+			fieldValue, ok := m[name]
+			if !ok {
+				continue
+			}
+			if isSecretField(field) {
+				if fieldValue != nil && fieldValue != "" {
+					m[name] = maskedSecretValue
+				}
+				continue
+			}
+			maskSecretFields(fieldValue, field.Type)
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := value.([]interface{}); ok {
+			for _, elem := range arr {
+				maskSecretFields(elem, t.Elem())
+			}
+		}
+	case reflect.Map:
+		if m, ok := value.(map[string]interface{}); ok {
+			for _, elem := range m {
+				maskSecretFields(elem, t.Elem())
+			}
+		}
+	}
+}
+
+func isSecretField(field reflect.StructField) bool {
+	value, ok := field.Tag.Lookup(tagSecret)
+	return ok && strings.ToLower(value) == "true"
+}
+
+func resolveSecretsInValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return resolveSecretString(v)
+	case map[string]interface{}:
+		for key, elem := range v {
+			resolved, err := resolveSecretsInValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []interface{}:
+		for i, elem := range v {
+			resolved, err := resolveSecretsInValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func resolveSecretString(value string) (string, error) {
+	if !strings.Contains(value, string(secretRefPrefix)) {
+		return value, nil
+	}
+	var resolveErr error
+	resolved := secretRefRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		parts := secretRefRegexp.FindStringSubmatch(match)
+		providerName, ref := parts[1], parts[2]
+		secretProvidersLock.RLock()
+		provider, ok := secretProviders[providerName]
+		secretProvidersLock.RUnlock()
+		if !ok {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("unknown secret provider '%s'", providerName)
+			}
+			return match
+		}
+		secret, err := provider.Resolve(ref)
+		if err != nil {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("resolve secret %s:%s: %v", providerName, ref, err)
+			}
+			return match
+		}
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// used in local config decoding
+func resolveSecretsHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	return resolveSecretString(reflect.ValueOf(data).String())
+}
+
+func localConfigDecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		resolveSecretsHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+func readFileSecret(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func readEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env variable %s is not set", name)
+	}
+	return value, nil
+}
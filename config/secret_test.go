@@ -0,0 +1,64 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type secretTestDatabase struct {
+	Username string
+	Password string `secret:"true"`
+}
+
+type secretTestConfig struct {
+	Database secretTestDatabase   `json:"database"`
+	Replicas []secretTestDatabase `json:"replicas"`
+	Token    string               `json:"token" secret:"true"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "password")
+	assert.NoError(ioutil.WriteFile(secretFile, []byte("file-secret\n"), 0600))
+	assert.NoError(os.Setenv("SECRET_TEST_TOKEN", "env-secret"))
+	defer os.Unsetenv("SECRET_TEST_TOKEN")
+	RegisterSecretProvider("static", SecretProviderFunc(func(ref string) (string, error) {
+		return "static-" + ref, nil
+	}))
+
+	data := []byte(`{"database":{"username":"user","password":"${secret:file:` + secretFile + `}"},` +
+		`"replicas":[{"password":"${secret:static:replica}"}],"token":"Bearer ${secret:env:SECRET_TEST_TOKEN}"}`)
+	resolved, err := ResolveSecrets(data)
+	assert.NoError(err)
+	cfg := secretTestConfig{}
+	assert.NoError(json.Unmarshal(resolved, &cfg))
+	assert.Equal("file-secret", cfg.Database.Password)
+	assert.Equal("static-replica", cfg.Replicas[0].Password)
+	assert.Equal("Bearer env-secret", cfg.Token)
+
+	_, err = ResolveSecrets([]byte(`{"token":"${secret:unknown:ref}"}`))
+	assert.Error(err)
+	_, err = ResolveSecrets([]byte(`{"token":"${secret:env:SECRET_TEST_NOT_SET}"}`))
+	assert.Error(err)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &secretTestConfig{
+		Database: secretTestDatabase{Username: "user", Password: "pass"},
+		Replicas: []secretTestDatabase{{Username: "replica", Password: "replica-pass"}},
+		Token:    "secret-token",
+	}
+	masked := MaskSecrets(cfg)
+	assert.JSONEq(`{"database":{"username":"user","password":"******"},`+
+		`"replicas":[{"username":"replica","password":"******"}],"token":"******"}`, masked)
+}
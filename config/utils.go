@@ -20,10 +20,18 @@ const (
 	Float32 PropertyType = "float32"
 	Float64 PropertyType = "float64"
 	String  PropertyType = "string"
+	Json    PropertyType = "json"
 )
 
 func castString(value string) (interface{}, error) {
 	v, t := getValueAndType(value)
+	if t == "" {
+		t = String
+	}
+	return castStringTo(v, t)
+}
+
+func castStringTo(v string, t PropertyType) (interface{}, error) {
 	switch t {
 	case String:
 		return v, nil
@@ -35,13 +43,20 @@ func castString(value string) (interface{}, error) {
 		return strconv.ParseFloat(v, 64)
 	case Bool:
 		return strconv.ParseBool(v)
+	case Json:
+		var value interface{}
+		if err := json.Unmarshal([]byte(v), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
 	default:
 		return nil, errors.New("unknown primitive type")
 	}
 }
 
+// returns empty type if value has no #{type} suffix
 func getValueAndType(value string) (string, PropertyType) {
-	t := String
+	t := PropertyType("")
 	v := valueTypeRegexp.ReplaceAllStringFunc(value, func(s string) string {
 		t = PropertyType(s[2 : len(s)-1])
 		return ""
@@ -54,7 +69,7 @@ func getEnvOverrides(envPrefix string) map[string]string {
 
 	vars := os.Environ()
 	for _, v := range vars {
-		pairs := strings.Split(v, "=")
+		pairs := strings.SplitN(v, "=", 2)
 		if len(pairs) >= 2 && strings.HasPrefix(pairs[0], envPrefix) {
 			path := pairs[0][len(envPrefix):]
 			path = strings.ToLower(path)
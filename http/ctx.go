@@ -1,9 +1,14 @@
 package http
 
-import "github.com/valyala/fasthttp"
+import (
+	"context"
+
+	"github.com/valyala/fasthttp"
+)
 
 type Ctx struct {
 	*fasthttp.RequestCtx
+	context            context.Context
 	m                  map[string]interface{}
 	mappedRequestBody  interface{}
 	mappedResponseBody interface{}
@@ -44,3 +49,8 @@ func (c *Ctx) Error() error {
 func (c *Ctx) Action() string {
 	return c.action
 }
+
+// context carrying trace of request, pass it to clients to propagate trace
+func (c *Ctx) Context() context.Context {
+	return c.context
+}
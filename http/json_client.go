@@ -1,6 +1,8 @@
 package http
 
 import (
+	"context"
+	"github.com/integration-system/isp-lib/v2/tracing"
 	"github.com/valyala/fasthttp"
 	"time"
 )
@@ -19,13 +21,20 @@ func WithDefaultTimeout(timeout time.Duration) JsonRestClientOption {
 	}
 }
 
+var _ RestClientWithContext = (*JsonRestClient)(nil)
+
 type JsonRestClient struct {
 	c              *fasthttp.Client
 	defaultTimeout time.Duration
 }
 
 func (jrc *JsonRestClient) Invoke(method, uri string, headers map[string]string, requestBody, responsePtr interface{}) error {
-	return jrc.do(method, uri, headers, requestBody, func(responseBody []byte) error {
+	return jrc.InvokeWithContext(context.Background(), method, uri, headers, requestBody, responsePtr)
+}
+
+// trace stored in ctx is propagated with traceparent and x-request-id headers
+func (jrc *JsonRestClient) InvokeWithContext(ctx context.Context, method, uri string, headers map[string]string, requestBody, responsePtr interface{}) error {
+	return jrc.do(ctx, method, uri, headers, requestBody, func(responseBody []byte) error {
 		if responsePtr != nil {
 			if err := json.Unmarshal(responseBody, responsePtr); err != nil {
 				return err
@@ -49,7 +58,7 @@ func (jrc *JsonRestClient) Get(uri string, responsePtr interface{}) error {
 
 func (jrc *JsonRestClient) InvokeWithDynamicResponse(method, uri string, headers map[string]string, requestBody interface{}) (interface{}, error) {
 	var res interface{}
-	err := jrc.do(method, uri, headers, requestBody, func(responseBody []byte) error {
+	err := jrc.do(context.Background(), method, uri, headers, requestBody, func(responseBody []byte) error {
 		if len(responseBody) == 0 {
 			return nil
 		}
@@ -77,7 +86,12 @@ func (jrc *JsonRestClient) InvokeWithDynamicResponse(method, uri string, headers
 	return res, nil
 }
 
-func (jrc *JsonRestClient) do(method, uri string, headers map[string]string, requestBody interface{}, respBodyHandler func([]byte) error) error {
+func (jrc *JsonRestClient) do(ctx context.Context, method, uri string, headers map[string]string, requestBody interface{}, respBodyHandler func([]byte) error) (err error) {
+	spanCtx, span := tracing.StartSpan(ctx, method+" "+uri, tracing.ClientSpan)
+	defer func() {
+		span.Finish(err)
+	}()
+
 	body, err := prepareRequestBody(requestBody)
 	if err != nil {
 		return err
@@ -89,6 +103,11 @@ func (jrc *JsonRestClient) do(method, uri string, headers map[string]string, req
 	defer fasthttp.ReleaseResponse(res)
 
 	prepareRequest(req, method, uri, headers, body)
+	tracing.Inject(spanCtx, func(key, value string) {
+		if len(req.Header.Peek(key)) == 0 {
+			req.Header.Set(key, value)
+		}
+	})
 
 	if err := jrc.c.DoTimeout(req, res, jrc.defaultTimeout); err != nil {
 		return err
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"github.com/golang/protobuf/ptypes/struct"
 	"google.golang.org/grpc/status"
@@ -37,3 +38,10 @@ type RestClient interface {
 	Get(uri string, responsePtr interface{}) error
 	InvokeWithDynamicResponse(method, uri string, headers map[string]string, requestBody interface{}) (interface{}, error)
 }
+
+// RestClientWithContext is implemented by RestClient returned from NewJsonRestClient,
+// trace stored in ctx is propagated with request headers
+type RestClientWithContext interface {
+	RestClient
+	InvokeWithContext(ctx context.Context, method, uri string, headers map[string]string, requestBody, responsePtr interface{}) error
+}
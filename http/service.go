@@ -1,9 +1,11 @@
 package http
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"github.com/integration-system/gowsdl/soap"
+	"github.com/integration-system/isp-lib/v2/tracing"
 	log "github.com/integration-system/isp-log"
 	"github.com/integration-system/isp-log/stdcodes"
 	"github.com/json-iterator/go"
@@ -127,6 +129,11 @@ func (ss *HttpService) handleRequest(ctx *fasthttp.RequestCtx) {
 	key := getActionKey(ctx)
 	fd, ok := ss.actions[key]
 	c := &Ctx{RequestCtx: ctx, m: make(map[string]interface{}), action: key}
+	spanCtx, span := tracing.StartSpan(tracing.Extract(context.Background(), func(key string) string {
+		return string(ctx.Request.Header.Peek(key))
+	}), key, tracing.ServerSpan)
+	c.context = spanCtx
+	c.Response.Header.Set(tracing.RequestIdHeader, tracing.RequestId(spanCtx))
 	if ok {
 		if fd.mType == SoapMType {
 			ss.handleSoapRequest(fd, c)
@@ -172,6 +179,9 @@ func (ss *HttpService) handleRequest(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	span.SetAttribute("statusCode", c.Response.StatusCode())
+	span.Finish(c.err)
+
 	for _, p := range ss.pp {
 		p(c)
 	}
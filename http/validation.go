@@ -1,10 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
-	"github.com/asaskevich/govalidator"
-	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/integration-system/isp-lib/v2/validation"
 )
 
 type ValidationErrors struct {
@@ -13,16 +13,16 @@ type ValidationErrors struct {
 }
 
 func validate(ctx *Ctx, value interface{}) error {
-	err := utils.ValidateV2(value)
-	if err == nil {
-		return nil
+	err := validation.Validate(value)
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		return err
 	}
-	m := govalidator.ErrorsByField(err)
 	return &ValidationErrors{
 		RESTFault: &RESTFault{
 			Code:   http.StatusBadRequest,
 			Status: http.StatusText(http.StatusBadRequest),
 		},
-		Details: m,
+		Details: errs.ByField(),
 	}
 }
@@ -0,0 +1,68 @@
+package metric
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	livenessPath  = "/health/live"
+	readinessPath = "/health/ready"
+)
+
+// returns true if probe passed and arbitrary details which are written to response body
+type HealthChecker func() (ok bool, details interface{})
+
+var (
+	livenessChecker  HealthChecker
+	readinessChecker HealthChecker
+	probesLock       sync.RWMutex
+)
+
+// checker is used in /health/live probe, probe always passed if checker is not set
+func SetLivenessChecker(checker HealthChecker) {
+	probesLock.Lock()
+	livenessChecker = checker
+	probesLock.Unlock()
+}
+
+// checker is used in /health/ready probe, probe always passed if checker is not set
+func SetReadinessChecker(checker HealthChecker) {
+	probesLock.Lock()
+	readinessChecker = checker
+	probesLock.Unlock()
+}
+
+func handleLivenessRequest(ctx *fasthttp.RequestCtx) {
+	probesLock.RLock()
+	checker := livenessChecker
+	probesLock.RUnlock()
+	handleProbe(ctx, checker)
+}
+
+func handleReadinessRequest(ctx *fasthttp.RequestCtx) {
+	probesLock.RLock()
+	checker := readinessChecker
+	probesLock.RUnlock()
+	handleProbe(ctx, checker)
+}
+
+func handleProbe(ctx *fasthttp.RequestCtx, checker HealthChecker) {
+	ok, details := true, interface{}(nil)
+	if checker != nil {
+		ok, details = checker()
+	}
+	statusCode := fasthttp.StatusOK
+	if !ok {
+		statusCode = fasthttp.StatusServiceUnavailable
+	}
+	bytes, _ := json.Marshal(map[string]interface{}{
+		"ok":      ok,
+		"details": details,
+	})
+	ctx.SetContentType("application/json")
+	ctx.SetBody(bytes)
+	ctx.SetStatusCode(statusCode)
+}
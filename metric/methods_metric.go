@@ -81,20 +81,20 @@ func NewMethodMetrics(metricsPrefix string, registry metrics.Registry) *MethodMe
 	}
 }
 
-func WithMetrics(metrics *MethodMetrics, next backend.Interceptor) backend.Interceptor {
+// MethodMetricsInterceptor catches duration and errors of each request
+func MethodMetricsInterceptor(metrics *MethodMetrics) backend.Interceptor {
 	return func(ctx backend.RequestCtx, proceed func() (interface{}, error)) (interface{}, error) {
-		var (
-			resp interface{}
-			err  error
-		)
 		now := time.Now()
-		if next != nil {
-			resp, err = next(ctx, proceed)
-		} else {
-			resp, err = proceed()
-		}
-		since := time.Since(now)
-		metrics.CatchMetric(ctx.Method(), since, err)
+		resp, err := proceed()
+		metrics.CatchMetric(ctx.Method(), time.Since(now), err)
 		return resp, err
 	}
 }
+
+// Deprecated: use DefaultService.Use with MethodMetricsInterceptor
+func WithMetrics(metrics *MethodMetrics, next backend.Interceptor) backend.Interceptor {
+	if next == nil {
+		return MethodMetricsInterceptor(metrics)
+	}
+	return backend.ChainInterceptors(MethodMetricsInterceptor(metrics), next)
+}
@@ -52,6 +52,8 @@ func InitHttpServer(metricConfig structure.MetricConfiguration) {
 
 	router := fasthttprouter.New()
 	router.GET(metricPath, handleMetricRequest)
+	router.GET(livenessPath, handleLivenessRequest)
+	router.GET(readinessPath, handleReadinessRequest)
 
 	router.GET(startProfilingPath, handleEnableProfilingRequest)
 	router.GET(stopProfilingPath, handleDisableProfilingRequest)
@@ -19,8 +19,9 @@ type MetricConfiguration struct {
 }
 
 type AddressConfiguration struct {
-	IP   string `json:"ip" schema:"Хост"`
-	Port string `json:"port" schema:"Порт"`
+	IP   string            `json:"ip" schema:"Хост"`
+	Port string            `json:"port" schema:"Порт"`
+	TLS  *TLSConfiguration `json:"tls,omitempty" schema:"Настройки TLS,если указаны, grpc сервер на этом адресе принимает только защищенные соединения"`
 }
 
 func (addressConfiguration *AddressConfiguration) GetAddress() string {
@@ -30,7 +31,7 @@ func (addressConfiguration *AddressConfiguration) GetAddress() string {
 type RedisConfiguration struct {
 	Address   AddressConfiguration `schema:"Адрес Redis"`
 	Username  string               `schema:"Логин"`
-	Password  string               `schema:"Пароль"`
+	Password  string               `schema:"Пароль" secret:"true"`
 	DefaultDB int                  `schema:"База данных по умолчанию"`
 	Sentinel  *RedisSentinel       `schema:"Настройки Sentinel"`
 }
@@ -40,7 +41,7 @@ type RedisSentinel struct {
 	SentinelAddresses []string `schema:"Список адресов,host:port"`
 	// Deprecated: для sentinel не нужен отдельный username
 	SentinelUsername string `schema:"Логин Sentinel,deprecated: поле больше не используется"`
-	SentinelPassword string `schema:"Пароль Sentinel"`
+	SentinelPassword string `schema:"Пароль Sentinel" secret:"true"`
 }
 
 type DBConfiguration struct {
@@ -49,7 +50,7 @@ type DBConfiguration struct {
 	Database     string `valid:"required~Required" schema:"Название базы данных"`
 	Port         string `valid:"required~Required" schema:"Порт"`
 	Username     string `schema:"Логин"`
-	Password     string `schema:"Пароль"`
+	Password     string `schema:"Пароль" secret:"true"`
 	PoolSize     int    `schema:"Количество соединений в пуле,по умолчанию 10 соединений на каждое ядро"`
 	CreateSchema bool   `schema:"Создание схемы,если включено, создает схему, если ее не существует"`
 }
@@ -61,14 +62,39 @@ type SocketConfiguration struct {
 	Secure    bool              `schema:"Защищенное соединение,если включено используется https"`
 	UrlParams map[string]string `schema:"Параметры"`
 	// Deprecated: unused
-	ConnectionString      string `schema:"Строка соединения"`
-	ConnectionReadLimitKB int64  `schema:"Максимальное количество килобайт на чтение,при превышении соединение закрывается с ошибкой"`
+	ConnectionString      string            `schema:"Строка соединения"`
+	ConnectionReadLimitKB int64             `schema:"Максимальное количество килобайт на чтение,при превышении соединение закрывается с ошибкой"`
+	TLS                   *TLSConfiguration `schema:"Настройки TLS,если указаны, используется защищенное соединение"`
+}
+
+// client auth modes of TLSConfiguration
+const (
+	TLSClientAuthNone             = "none"
+	TLSClientAuthRequest          = "request"
+	TLSClientAuthRequire          = "require"
+	TLSClientAuthVerifyIfGiven    = "verify_if_given"
+	TLSClientAuthRequireAndVerify = "require_and_verify"
+)
+
+type TLSConfiguration struct {
+	CertFile           string `json:"certFile" schema:"Сертификат,путь к PEM файлу сертификата, для клиента используется при mTLS"`
+	KeyFile            string `json:"keyFile" schema:"Ключ,путь к PEM файлу закрытого ключа сертификата"`
+	CAFile             string `json:"caFile" schema:"Корневые сертификаты,путь к PEM файлу доверенных сертификатов, по умолчанию используются системные"`
+	ServerName         string `json:"serverName" schema:"Имя сервера,имя для проверки сертификата сервера, по умолчанию используется адрес подключения"`
+	ClientAuth         string `json:"clientAuth" schema:"Проверка сертификата клиента,none|request|require|verify_if_given|require_and_verify, по умолчанию none"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" schema:"Отключить проверку сертификата сервера"`
+}
+
+type StandaloneConfiguration struct {
+	Enabled          bool   `schema:"Автономный режим,если включено, модуль запускается без подключения к сервису конфигурации"`
+	RemoteConfigPath string `schema:"Путь к удаленной конфигурации,JSON файл, по умолчанию используется путь к удаленной конфигурации по умолчанию"`
+	EnvironmentPath  string `schema:"Путь к файлу окружения,YAML/JSON файл с маршрутами и адресами требуемых модулей"`
 }
 
 type ElasticConfiguration struct {
 	URL         string `schema:"Адрес"`
 	Username    string `schema:"Логин"`
-	Password    string `schema:"Пароль"`
+	Password    string `schema:"Пароль" secret:"true"`
 	Sniff       *bool  `schema:"Механизм поиска нод в кластере,если включено, клиент подключается ко всем нодам в кластере"`
 	Healthcheck *bool  `schema:"Проверка работоспособности нод,если включено, пингует ноды"`
 }
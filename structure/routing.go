@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"path"
+	"time"
 )
 
 type ModuleInfo struct {
@@ -27,7 +28,21 @@ type EndpointDescriptor struct {
 	Inner            bool   `json:"inner"`
 	UserAuthRequired bool
 	Extra            map[string]interface{}
-	Handler          interface{} `json:"-"`
+	Handler          interface{}    `json:"-"`
+	Limits           EndpointLimits `json:"-"`
+}
+
+// server side limits of endpoint handling, zero values mean unlimited
+type EndpointLimits struct {
+	// max execution time of interceptors and handler, DeadlineExceeded is returned on exceeding.
+	// Handler which ignores context keeps running after timeout and holds MaxInFlight slot until it returns
+	Timeout time.Duration
+	// max concurrently handled requests
+	MaxInFlight int
+	// max requests waiting for free slot when MaxInFlight is reached, others are rejected with ResourceExhausted
+	MaxQueue int
+	// max time of waiting in queue, if it is zero request waits until its context is done
+	QueueTimeout time.Duration
 }
 
 type ModuleDependency struct {
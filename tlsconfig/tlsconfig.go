@@ -0,0 +1,277 @@
+// Package tlsconfig builds TLS credentials from structure.TLSConfiguration for grpc servers and clients and
+// http clients. Certificate, key and CA files are reloaded on change, new handshakes use new certificates
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/integration-system/isp-lib/v2/structure"
+	log "github.com/integration-system/isp-log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	tlsReloadEvent = 91
+	// events of sequential writes and atomic replaces of files are merged into single reload
+	reloadDelay = 100 * time.Millisecond
+)
+
+type certificates struct {
+	cert *tls.Certificate
+	// nil means system roots
+	roots *x509.CertPool
+}
+
+// Loader holds actual certificates of configuration
+type Loader struct {
+	cfg        structure.TLSConfiguration
+	clientAuth tls.ClientAuthType
+	certs      atomic.Value
+
+	watcher   *fsnotify.Watcher
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewLoader reads files of configuration and watches them, Close must be called to stop watching
+func NewLoader(cfg structure.TLSConfiguration) (*Loader, error) {
+	clientAuth, err := parseClientAuth(cfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	l := &Loader{
+		cfg:        cfg,
+		clientAuth: clientAuth,
+		done:       make(chan struct{}),
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	l.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.WithMessage(err, "create tls files watcher")
+	}
+	watchedDirs := make(map[string]bool)
+	for _, path := range []string{cfg.CertFile, cfg.KeyFile, cfg.CAFile} {
+		dir := filepath.Dir(filepath.Clean(path))
+		if path == "" || watchedDirs[dir] {
+			continue
+		}
+		watchedDirs[dir] = true
+		// watch directory to handle mounted secrets which swap symlinked directory instead of writing files,
+		// so any change in directory triggers reload
+		if err := l.watcher.Add(dir); err != nil {
+			_ = l.watcher.Close()
+			return nil, errors.WithMessagef(err, "watch %s", path)
+		}
+	}
+	go l.watch()
+	return l, nil
+}
+
+// Reload reads files of configuration, previous certificates are kept on error
+func (l *Loader) Reload() error {
+	return l.load()
+}
+
+func (l *Loader) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		err = l.watcher.Close()
+		<-l.done
+	})
+	return err
+}
+
+// ServerConfig returns config for server side, certificate is required
+func (l *Loader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certs := l.current()
+			if certs.cert == nil {
+				return nil, errors.New("tls certificate is not configured")
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*certs.cert},
+				ClientAuth:   l.clientAuth,
+				ClientCAs:    certs.roots,
+			}, nil
+		},
+	}
+}
+
+// ServerCredentials returns grpc credentials of server
+func (l *Loader) ServerCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(l.ServerConfig())
+}
+
+// ClientCredentials returns grpc credentials of client, server name is host of address if it is not configured
+func (l *Loader) ClientCredentials() credentials.TransportCredentials {
+	return &clientCredentials{
+		TransportCredentials: credentials.NewTLS(l.clientConfig("")),
+		loader:               l,
+	}
+}
+
+// HTTPTransport returns transport which dials TLS connections with actual certificates
+func (l *Loader) HTTPTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialTLSContext = l.dialTLSContext
+	return transport
+}
+
+func (l *Loader) dialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := new(net.Dialer).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, l.clientConfig(hostname(addr)))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// clientConfig is built for every connection to use reloaded CA with standard verification
+func (l *Loader) clientConfig(serverName string) *tls.Config {
+	certs := l.current()
+	if l.cfg.ServerName != "" {
+		serverName = l.cfg.ServerName
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		RootCAs:            certs.roots,
+		InsecureSkipVerify: l.cfg.InsecureSkipVerify,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert := l.current().cert; cert != nil {
+				return cert, nil
+			}
+			return &tls.Certificate{}, nil
+		},
+	}
+}
+
+type clientCredentials struct {
+	credentials.TransportCredentials
+	loader *Loader
+}
+
+func (c *clientCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(c.loader.clientConfig(hostname(authority))).ClientHandshake(ctx, authority, conn)
+}
+
+func (c *clientCredentials) Clone() credentials.TransportCredentials {
+	return &clientCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		loader:               c.loader,
+	}
+}
+
+func hostname(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func (l *Loader) current() certificates {
+	return l.certs.Load().(certificates)
+}
+
+func (l *Loader) load() error {
+	certs := certificates{}
+	if l.cfg.CertFile != "" || l.cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+		if err != nil {
+			return errors.WithMessage(err, "load tls certificate")
+		}
+		certs.cert = &cert
+	}
+	if l.cfg.CAFile != "" {
+		pem, err := os.ReadFile(l.cfg.CAFile)
+		if err != nil {
+			return errors.WithMessage(err, "read tls ca")
+		}
+		certs.roots = x509.NewCertPool()
+		if !certs.roots.AppendCertsFromPEM(pem) {
+			return errors.Errorf("no certificates found in %s", l.cfg.CAFile)
+		}
+	}
+	l.certs.Store(certs)
+	return nil
+}
+
+func (l *Loader) watch() {
+	defer close(l.done)
+	var timer *time.Timer
+	var reload <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case event, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(reloadDelay)
+			reload = timer.C
+		case <-reload:
+			reload = nil
+			if err := l.load(); err != nil {
+				// files may be partially written, the next event reloads them
+				log.Errorf(tlsReloadEvent, "could not reload tls certificates: %v", err)
+			} else {
+				log.Info(tlsReloadEvent, "tls certificates reloaded")
+			}
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf(tlsReloadEvent, "tls files watcher: %v", err)
+		}
+	}
+}
+
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", structure.TLSClientAuthNone:
+		return tls.NoClientCert, nil
+	case structure.TLSClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case structure.TLSClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case structure.TLSClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case structure.TLSClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, errors.Errorf("unknown tls client auth mode %s", mode)
+	}
+}
@@ -0,0 +1,294 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integration-system/isp-lib/v2/backend"
+	"github.com/integration-system/isp-lib/v2/structure"
+	"github.com/integration-system/isp-lib/v2/tlsconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, dir, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	writePem(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue writes certificate and key files signed by ca, certificate is valid for 127.0.0.1
+func (ca *testCA) issue(t *testing.T, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	writePem(t, keyFile, "EC PRIVATE KEY", keyDer)
+	writePem(t, certFile, "CERTIFICATE", der)
+}
+
+func writePem(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func startTLSServer(t *testing.T, loader *tlsconfig.Loader) (structure.AddressConfiguration, *backend.GrpcServer) {
+	service := backend.NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "ping", Handler: func() string { return "pong" }},
+	})
+	srv := backend.NewGrpcServer("tls", structure.AddressConfiguration{IP: "127.0.0.1", Port: "0"}, service,
+		backend.WithServerTLS(loader))
+	require.NoError(t, srv.Start(context.Background()))
+	port := strings.Split(srv.Addr().String(), ":")[1]
+	return structure.AddressConfiguration{IP: "127.0.0.1", Port: port}, srv
+}
+
+func ping(loader *tlsconfig.Loader, addr structure.AddressConfiguration) error {
+	cli := backend.NewRxGrpcClient(backend.WithClientTLS(loader))
+	defer cli.Close()
+	cli.ReceiveAddressList([]structure.AddressConfiguration{addr})
+	resp := ""
+	return cli.Invoke("ping", 1, nil, &resp, backend.WithTimeout(time.Second))
+}
+
+func TestMutualTLS(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	ca.issue(t, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+	ca.issue(t, filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key"))
+
+	serverLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{
+		CertFile:   filepath.Join(dir, "server.pem"),
+		KeyFile:    filepath.Join(dir, "server.key"),
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		ClientAuth: structure.TLSClientAuthRequireAndVerify,
+	})
+	require.NoError(t, err)
+	defer serverLoader.Close()
+	addr, srv := startTLSServer(t, serverLoader)
+	defer srv.Stop()
+
+	clientLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+		CAFile:   filepath.Join(dir, "ca.pem"),
+	})
+	require.NoError(t, err)
+	defer clientLoader.Close()
+	assert.NoError(ping(clientLoader, addr))
+
+	// client without certificate
+	anonymousLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CAFile: filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	defer anonymousLoader.Close()
+	assert.Equal(codes.Unavailable, status.Code(ping(anonymousLoader, addr)))
+
+	// server certificate is not trusted
+	otherDir := t.TempDir()
+	newTestCA(t, otherDir, "other")
+	untrustedLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+		CAFile:   filepath.Join(otherDir, "other.pem"),
+	})
+	require.NoError(t, err)
+	defer untrustedLoader.Close()
+	assert.Equal(codes.Unavailable, status.Code(ping(untrustedLoader, addr)))
+}
+
+func TestAddressTLS(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	ca.issue(t, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+
+	service := backend.NewDefaultService([]structure.EndpointDescriptor{
+		{Path: "ping", Handler: func() string { return "pong" }},
+	})
+	srv := backend.NewGrpcServer("tls", structure.AddressConfiguration{
+		IP:   "127.0.0.1",
+		Port: "0",
+		TLS: &structure.TLSConfiguration{
+			CertFile: filepath.Join(dir, "server.pem"),
+			KeyFile:  filepath.Join(dir, "server.key"),
+		},
+	}, service)
+	require.NoError(t, srv.Start(context.Background()))
+	defer func() {
+		assert.NoError(srv.Shutdown(context.Background()))
+	}()
+	addr := structure.AddressConfiguration{IP: "127.0.0.1", Port: strings.Split(srv.Addr().String(), ":")[1]}
+
+	cli := backend.NewRxGrpcClient(backend.WithClientTLSConfig(structure.TLSConfiguration{CAFile: filepath.Join(dir, "ca.pem")}))
+	defer cli.Close()
+	cli.ReceiveAddressList([]structure.AddressConfiguration{addr})
+	resp := ""
+	assert.NoError(cli.Invoke("ping", 1, nil, &resp, backend.WithTimeout(time.Second)))
+	assert.Equal("pong", resp)
+
+	// plain text client is rejected
+	plain := backend.NewRxGrpcClient(backend.WithDialOptions(grpc.WithInsecure()))
+	defer plain.Close()
+	plain.ReceiveAddressList([]structure.AddressConfiguration{addr})
+	assert.Equal(codes.Unavailable, status.Code(plain.Invoke("ping", 1, nil, &resp, backend.WithTimeout(time.Second))))
+
+	// tls files error is returned by Start
+	invalid := backend.NewGrpcServer("invalid", structure.AddressConfiguration{
+		IP:   "127.0.0.1",
+		Port: "0",
+		TLS:  &structure.TLSConfiguration{CertFile: filepath.Join(dir, "absent.pem")},
+	}, service)
+	assert.Error(invalid.Start(context.Background()))
+	assert.NoError(invalid.Shutdown(context.Background()))
+}
+
+func TestLoader_HotReload(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	oldCA := newTestCA(t, dir, "old")
+	newCA := newTestCA(t, dir, "new")
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	oldCA.issue(t, certFile, keyFile)
+
+	serverLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	defer serverLoader.Close()
+	addr, srv := startTLSServer(t, serverLoader)
+	defer srv.Stop()
+
+	clientLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CAFile: filepath.Join(dir, "new.pem")})
+	require.NoError(t, err)
+	defer clientLoader.Close()
+	assert.Error(ping(clientLoader, addr))
+
+	newCA.issue(t, certFile, keyFile)
+	assert.Eventually(func() bool {
+		return ping(clientLoader, addr) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+// files are mounted like kubernetes secret: cert and key are symlinks to ..data/ which is symlink to versioned directory,
+// rotation swaps ..data atomically
+func TestLoader_HotReloadSymlinkedDir(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	caDir := t.TempDir()
+	oldCA := newTestCA(t, caDir, "old")
+	newCA := newTestCA(t, caDir, "new")
+
+	oldVersion := filepath.Join(dir, "..v1")
+	require.NoError(t, os.Mkdir(oldVersion, 0700))
+	oldCA.issue(t, filepath.Join(oldVersion, "server.pem"), filepath.Join(oldVersion, "server.key"))
+	require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "server.pem"), certFile))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "server.key"), keyFile))
+
+	serverLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	defer serverLoader.Close()
+	addr, srv := startTLSServer(t, serverLoader)
+	defer srv.Stop()
+
+	clientLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CAFile: filepath.Join(caDir, "new.pem")})
+	require.NoError(t, err)
+	defer clientLoader.Close()
+	assert.Error(ping(clientLoader, addr))
+
+	newVersion := filepath.Join(dir, "..v2")
+	require.NoError(t, os.Mkdir(newVersion, 0700))
+	newCA.issue(t, filepath.Join(newVersion, "server.pem"), filepath.Join(newVersion, "server.key"))
+	require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	require.NoError(t, os.RemoveAll(oldVersion))
+	assert.Eventually(func() bool {
+		return ping(clientLoader, addr) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestLoader_HTTPTransport(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	ca.issue(t, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+
+	serverLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{
+		CertFile: filepath.Join(dir, "server.pem"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	})
+	require.NoError(t, err)
+	defer serverLoader.Close()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	srv.TLS = serverLoader.ServerConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	clientLoader, err := tlsconfig.NewLoader(structure.TLSConfiguration{CAFile: filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	defer clientLoader.Close()
+	cli := &http.Client{Transport: clientLoader.HTTPTransport()}
+	resp, err := cli.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.NoError(err)
+	assert.Equal("ok", string(body))
+}
+
+func TestNewLoader_InvalidConfig(t *testing.T) {
+	_, err := tlsconfig.NewLoader(structure.TLSConfiguration{ClientAuth: "always"})
+	assert.Error(t, err)
+	_, err = tlsconfig.NewLoader(structure.TLSConfiguration{CertFile: "not_exists.pem", KeyFile: "not_exists.key"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"sync/atomic"
+
+	log "github.com/integration-system/isp-log"
+)
+
+const (
+	traceSpanEvent = 90
+)
+
+type Exporter interface {
+	Export(span *Span)
+}
+
+type ExporterFunc func(span *Span)
+
+func (f ExporterFunc) Export(span *Span) {
+	f(span)
+}
+
+type exporterHolder struct {
+	Exporter
+}
+
+var exporter atomic.Value
+
+func init() {
+	exporter.Store(exporterHolder{NoopExporter()})
+}
+
+// SetExporter sets exporter of finished spans, nil disables exporting
+func SetExporter(e Exporter) {
+	if e == nil {
+		e = NoopExporter()
+	}
+	exporter.Store(exporterHolder{e})
+}
+
+func GetExporter() Exporter {
+	return exporter.Load().(exporterHolder).Exporter
+}
+
+func NoopExporter() Exporter {
+	return ExporterFunc(func(span *Span) {})
+}
+
+// LogExporter writes finished spans to log, spans of the same request have the same traceId and requestId
+func LogExporter() Exporter {
+	return ExporterFunc(func(span *Span) {
+		metadata := log.Metadata{
+			"traceId":   span.Context.TraceId,
+			"spanId":    span.Context.SpanId,
+			"requestId": span.Context.RequestId,
+			"kind":      span.Kind,
+			"duration":  span.Duration().String(),
+		}
+		if span.ParentSpanId != "" {
+			metadata["parentSpanId"] = span.ParentSpanId
+		}
+		for key, value := range span.Attributes {
+			metadata[key] = value
+		}
+		if span.Err != nil {
+			metadata["error"] = span.Err.Error()
+		}
+		log.WithMetadata(metadata).Infof(traceSpanEvent, "span %s", span.Name)
+	})
+}
@@ -0,0 +1,205 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// W3C trace context header, https://www.w3.org/TR/trace-context/
+	TraceparentHeader = "traceparent"
+	RequestIdHeader   = "x-request-id"
+
+	traceparentVersion = "00"
+	sampledFlag        = 0x01
+)
+
+type SpanKind string
+
+const (
+	ServerSpan   SpanKind = "server"
+	ClientSpan   SpanKind = "client"
+	InternalSpan SpanKind = "internal"
+)
+
+type spanContextKey struct{}
+
+// SpanContext identifies span within trace and is propagated between modules
+type SpanContext struct {
+	TraceId   string
+	SpanId    string
+	Sampled   bool
+	RequestId string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return isValidId(sc.TraceId, 32) && isValidId(sc.SpanId, 16)
+}
+
+// Traceparent formats span context as value of traceparent header
+func (sc SpanContext) Traceparent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = sampledFlag
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceId, sc.SpanId, flags)
+}
+
+// ParseTraceparent parses traceparent header value, returns false if value is malformed
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if parts[0] == traceparentVersion && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc := SpanContext{
+		TraceId: parts[1],
+		SpanId:  parts[2],
+		Sampled: flags[0]&sampledFlag != 0,
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// Span is a single traced operation, it is passed to Exporter on Finish
+type Span struct {
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanId string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          error
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]interface{})
+	}
+	s.Attributes[key] = value
+}
+
+func (s *Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Finish records span end and error and exports sampled span
+func (s *Span) Finish(err error) {
+	s.End = time.Now()
+	s.Err = err
+	if s.Context.Sampled {
+		GetExporter().Export(s)
+	}
+}
+
+// StartSpan starts child span of span context stored in ctx or new trace root if there is none,
+// root span keeps request id of ctx. Returned context carries new span context
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{
+		Name:  name,
+		Kind:  kind,
+		Start: time.Now(),
+	}
+	parent, _ := FromContext(ctx)
+	if parent.IsValid() {
+		span.ParentSpanId = parent.SpanId
+		span.Context = SpanContext{
+			TraceId:   parent.TraceId,
+			SpanId:    newId(8),
+			Sampled:   parent.Sampled,
+			RequestId: parent.RequestId,
+		}
+	} else {
+		span.Context = SpanContext{
+			TraceId:   newId(16),
+			SpanId:    newId(8),
+			Sampled:   true,
+			RequestId: parent.RequestId,
+		}
+	}
+	if span.Context.RequestId == "" {
+		span.Context.RequestId = span.Context.TraceId
+	}
+	return ContextWithSpanContext(ctx, span.Context), span
+}
+
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// RequestId returns request id of trace stored in ctx or empty string
+func RequestId(ctx context.Context) string {
+	sc, _ := FromContext(ctx)
+	return sc.RequestId
+}
+
+// Extract reads remote span context from request headers using get, malformed traceparent is ignored.
+// Without traceparent only request id is stored, so StartSpan begins new trace root with it
+func Extract(ctx context.Context, get func(key string) string) context.Context {
+	requestId := get(RequestIdHeader)
+	sc, ok := ParseTraceparent(get(TraceparentHeader))
+	if !ok {
+		if requestId == "" {
+			return ctx
+		}
+		sc = SpanContext{}
+	}
+	sc.RequestId = requestId
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// Inject writes span context stored in ctx to request headers using set
+func Inject(ctx context.Context, set func(key, value string)) {
+	sc, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	if sc.IsValid() {
+		set(TraceparentHeader, sc.Traceparent())
+	}
+	if sc.RequestId != "" {
+		set(RequestIdHeader, sc.RequestId)
+	}
+}
+
+func newId(bytesLen int) string {
+	b := make([]byte, bytesLen)
+	for {
+		_, _ = rand.Read(b)
+		for _, v := range b {
+			if v != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+func isValidId(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	assert := assert.New(t)
+
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(ok)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceId)
+	assert.Equal("00f067aa0ba902b7", sc.SpanId)
+	assert.True(sc.Sampled)
+	assert.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, ok := ParseTraceparent(value)
+		assert.False(ok, value)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	assert := assert.New(t)
+
+	incoming := map[string]string{
+		TraceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		RequestIdHeader:   "req-1",
+	}
+	ctx := Extract(context.Background(), func(key string) string {
+		return incoming[key]
+	})
+
+	exported := make([]*Span, 0)
+	SetExporter(ExporterFunc(func(span *Span) {
+		exported = append(exported, span)
+	}))
+	defer SetExporter(nil)
+
+	ctx, span := StartSpan(ctx, "method", ServerSpan)
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.Context.TraceId)
+	assert.Equal("00f067aa0ba902b7", span.ParentSpanId)
+	assert.Equal("req-1", RequestId(ctx))
+
+	outgoing := make(map[string]string)
+	Inject(ctx, func(key, value string) {
+		outgoing[key] = value
+	})
+	assert.Equal(span.Context.Traceparent(), outgoing[TraceparentHeader])
+	assert.Equal("req-1", outgoing[RequestIdHeader])
+
+	span.Finish(errors.New("failed"))
+	if assert.Len(exported, 1) {
+		assert.EqualError(exported[0].Err, "failed")
+	}
+}
+
+func TestStartSpanRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, span := StartSpan(context.Background(), "root", InternalSpan)
+	assert.True(span.Context.IsValid())
+	assert.Empty(span.ParentSpanId)
+	assert.Equal(span.Context.TraceId, RequestId(ctx))
+
+	// request id without traceparent starts new trace
+	ctx = Extract(context.Background(), func(key string) string {
+		if key == RequestIdHeader {
+			return "req-2"
+		}
+		return ""
+	})
+	outgoing := make(map[string]string)
+	Inject(ctx, func(key, value string) {
+		outgoing[key] = value
+	})
+	assert.Equal(map[string]string{RequestIdHeader: "req-2"}, outgoing)
+	_, span = StartSpan(ctx, "child", ServerSpan)
+	assert.True(span.Context.IsValid())
+	assert.Empty(span.ParentSpanId)
+	assert.Equal("req-2", span.Context.RequestId)
+}
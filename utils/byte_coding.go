@@ -18,13 +18,13 @@ var (
 )
 
 func init() {
-	extra.SetNamingStrategy(toCamelCase)
+	extra.SetNamingStrategy(ToCamelCase)
 
 	tc := &timeCoder{}
 	timeType := reflect2.TypeByName("time.Time")
 
 	encExt := jsoniter.EncoderExtension{timeType: tc}
-	decExt := jsoniter.DecoderExtension{timeType: tc}
+	decExt := jsoniter.DecoderExtension{timeType: tc, reflect2.TypeOf(time.Duration(0)): &durationDecoder{}}
 	ji.RegisterExtension(encExt)
 	ji.RegisterExtension(decExt)
 }
@@ -37,7 +37,8 @@ func ConvertGoToBytes(data interface{}) ([]byte, error) {
 	return ji.Marshal(data)
 }
 
-func toCamelCase(s string) string {
+// ToCamelCase is naming strategy of json fields without tag, first letter is lowered
+func ToCamelCase(s string) string {
 	if s == empty {
 		return s
 	}
@@ -67,3 +68,24 @@ func (codec *timeCoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	ts := *((*time.Time)(ptr))
 	stream.WriteString(ts.Format(FullDateFormat))
 }
+
+// durationDecoder decodes time.Duration from integer nanoseconds or duration string like "1m30s",
+// durations are still encoded as integers
+type durationDecoder struct {
+}
+
+func (codec *durationDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		d, err := time.ParseDuration(iter.ReadString())
+		if err != nil {
+			iter.ReportError("string -> time.Duration", err.Error())
+		} else {
+			*((*time.Duration)(ptr)) = d
+		}
+	case jsoniter.NilValue:
+		iter.Skip()
+	default:
+		*((*time.Duration)(ptr)) = time.Duration(iter.ReadInt64())
+	}
+}
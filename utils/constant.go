@@ -12,6 +12,10 @@ var (
 	EnvMigrationPath = os.Getenv("APP_MIGRATION_PATH")
 	DEV              = strings.ToLower(os.Getenv("APP_MODE")) == "dev"
 	LOG_LEVEL        = os.Getenv("LOG_LEVEL")
+
+	EnvStandalone                 = strings.ToLower(os.Getenv("APP_STANDALONE")) == "true"
+	EnvStandaloneRemoteConfigPath = os.Getenv("APP_STANDALONE_REMOTE_CONFIG_PATH")
+	EnvStandaloneEnvironmentPath  = os.Getenv("APP_STANDALONE_ENVIRONMENT_PATH")
 )
 
 const (
@@ -30,6 +34,10 @@ const (
 	// ===== GRPC =====
 	ProxyMethodNameHeader = "proxy_method_name"
 	MethodDefaultGroup    = "api"
+	// encoding of isp.Message body in request metadata and response header, see backend.ContentTypeProtobuf
+	BodyContentTypeHeader = "x-body-content-type"
+	// encoding of response body accepted by client
+	BodyAcceptHeader = "x-body-accept"
 
 	ApplicationIdHeader = "x-application-identity"
 	UserIdHeader        = "x-user-identity"
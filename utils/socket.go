@@ -23,6 +23,8 @@ const (
 	ModuleSendRequirements = "MODULE:SEND_REQUIREMENTS"
 	ModuleUpdateRoutes     = "MODULE:UPDATE_ROUTES"
 	ModuleSendConfigSchema = "MODULE:SEND_CONFIG_SCHEMA"
+	// sent without acknowledgement for compatibility with config services which do not handle it
+	ModuleConfigApplyResult = "MODULE:CONFIG_APPLY_RESULT"
 
 	ModuleConnectionSuffix = "MODULE_CONNECTED"
 
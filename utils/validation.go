@@ -57,6 +57,7 @@ func CreateValidationErrorDetailsV2(errorCode codes.Code, errorMessage string, d
 	return CreateValidationErrorDetails(errorCode, errorMessage, errors)
 }
 
+// Deprecated: stops at the first invalid element of slice, use validation.Validate
 func ValidateV2(value interface{}) error {
 	rt := reflect.TypeOf(value)
 	val := reflect.ValueOf(value)
@@ -81,6 +82,7 @@ func ValidateV2(value interface{}) error {
 	return nil
 }
 
+// Deprecated: use validation.Validate
 func Validate(value interface{}) error {
 	err := ValidateV2(value)
 	errors := govalidator.ErrorsByField(err)
@@ -0,0 +1,38 @@
+package validation
+
+import (
+	"strings"
+)
+
+// Violation of rule by field, Field is json path like "items[0].name", empty for root value
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Errors []Violation
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, v := range e {
+		if v.Field == "" {
+			messages[i] = v.Message
+		} else {
+			messages[i] = v.Field + ": " + v.Message
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ByField returns field -> message map, messages of the same field are joined
+func (e Errors) ByField() map[string]string {
+	m := make(map[string]string, len(e))
+	for _, v := range e {
+		if prev, ok := m[v.Field]; ok {
+			m[v.Field] = prev + "; " + v.Message
+		} else {
+			m[v.Field] = v.Message
+		}
+	}
+	return m
+}
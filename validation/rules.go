@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterRule("eqfield", EqField)
+	RegisterRule("nefield", NeField)
+	RegisterRule("requiredwith", RequiredWith)
+}
+
+// EqField requires value equal to value of sibling field named in param, e.g. `valid:"eqfield(Password)"`
+func EqField(field Field) error {
+	other, err := siblingField(field)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(field.Value.Interface(), other.Interface()) {
+		return errors.Errorf("must be equal to %s", field.Param)
+	}
+	return nil
+}
+
+// NeField requires value not equal to value of sibling field named in param
+func NeField(field Field) error {
+	other, err := siblingField(field)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(field.Value.Interface(), other.Interface()) {
+		return errors.Errorf("must not be equal to %s", field.Param)
+	}
+	return nil
+}
+
+// RequiredWith requires value if sibling field named in param is not empty, e.g. `valid:"requiredwith(EndDate)"`
+func RequiredWith(field Field) error {
+	other, err := siblingField(field)
+	if err != nil {
+		return err
+	}
+	if isEmpty(field.Value) && !isEmpty(other) {
+		return errors.Errorf("required with %s", field.Param)
+	}
+	return nil
+}
+
+func siblingField(field Field) (reflect.Value, error) {
+	if field.Parent.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("field has no parent structure")
+	}
+	other := field.Parent.FieldByName(field.Param)
+	if !other.IsValid() {
+		return reflect.Value{}, errors.Errorf("unknown field %s", field.Param)
+	}
+	return other, nil
+}
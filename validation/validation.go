@@ -0,0 +1,377 @@
+// Package validation validates request structures and collects all violations with json paths of fields.
+//
+// Rules are declared in `valid` tag with govalidator syntax, e.g. `valid:"required~Required,length(1|255)"`,
+// govalidator rules and rules registered with RegisterRule are supported. Structures may implement Validatable
+// for struct level checks
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/integration-system/isp-lib/v2/utils"
+	"github.com/pkg/errors"
+)
+
+const tagName = "valid"
+
+var (
+	paramRuleRegex = regexp.MustCompile(`^([^(]+)\((.*)\)$`)
+
+	rulesLock = sync.RWMutex{}
+	rules     = make(map[string]RuleFunc)
+)
+
+// Validatable is implemented by structures with struct level or cross field checks, Validate is called after rules
+// of fields. Violations of returned Errors are relative to structure, other errors are reported at structure path.
+// Methods with pointer receiver are called only for addressable values, so pass pointer to Validate
+type Validatable interface {
+	Validate() error
+}
+
+// Field is value of validated field
+type Field struct {
+	Value reflect.Value
+	// structure which contains field, it is used by cross field rules
+	Parent reflect.Value
+	// value in parentheses of rule, e.g. "Password" for eqfield(Password)
+	Param string
+	// json path of field
+	Path string
+}
+
+// RuleFunc returns error with violation description if value is invalid
+type RuleFunc func(field Field) error
+
+// RegisterRule adds rule which can be used in `valid` tag, registered rules take precedence over govalidator ones.
+// Rules are applied to not empty values only except rules of required family
+func RegisterRule(name string, rule RuleFunc) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	rules[name] = rule
+}
+
+func getRule(name string) (RuleFunc, bool) {
+	rulesLock.RLock()
+	defer rulesLock.RUnlock()
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+// Validate checks value and all nested structures, slices and maps, returns Errors with all found violations.
+// Unknown rule in tag is programmer error, it is returned as error of other type
+func Validate(value interface{}) error {
+	v := &validator{}
+	v.validate(reflect.ValueOf(value), "")
+	if v.err != nil {
+		return v.err
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	errs Errors
+	// invalid tag, validation is stopped
+	err error
+}
+
+func (v *validator) add(path, message string) {
+	v.errs = append(v.errs, Violation{Field: path, Message: message})
+}
+
+func (v *validator) validate(val reflect.Value, path string) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		v.validateStruct(val, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.validate(val.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			v.validate(iter.Value(), joinPath(path, fmt.Sprint(iter.Key().Interface())))
+		}
+	}
+}
+
+func (v *validator) validateStruct(val reflect.Value, path string) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+		fieldPath := path
+		if !f.Anonymous || f.Tag.Get("json") != "" {
+			name, ok := jsonName(f)
+			if !ok {
+				continue
+			}
+			fieldPath = joinPath(path, name)
+		}
+
+		field := Field{Value: val.Field(i), Parent: val, Path: fieldPath}
+		if tag != "" && field.Value.CanInterface() && !v.validateField(field, tag) {
+			continue
+		}
+		if v.err != nil {
+			return
+		}
+		v.validate(field.Value, fieldPath)
+	}
+
+	v.validateStructLevel(val, path)
+}
+
+func (v *validator) validateStructLevel(val reflect.Value, path string) {
+	if !val.CanInterface() {
+		return
+	}
+	var validatable Validatable
+	if val.CanAddr() {
+		validatable, _ = val.Addr().Interface().(Validatable)
+	}
+	if validatable == nil {
+		validatable, _ = val.Interface().(Validatable)
+	}
+	if validatable == nil {
+		return
+	}
+
+	err := validatable.Validate()
+	if err == nil {
+		return
+	}
+	var errs Errors
+	if errors.As(err, &errs) {
+		for _, violation := range errs {
+			v.add(joinPath(path, violation.Field), violation.Message)
+		}
+		return
+	}
+	v.add(path, err.Error())
+}
+
+// validateField applies tag rules, returns false if required value is missing
+func (v *validator) validateField(field Field, tag string) bool {
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		spec, message := option, ""
+		if i := strings.Index(option, "~"); i >= 0 {
+			spec, message = option[:i], option[i+1:]
+		}
+
+		if !v.checkRule(field, spec, message) && spec == "required" {
+			return false
+		}
+		if v.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRule adds violation if field doesn't satisfy rule, returns false then.
+// As in govalidator, pointers are dereferenced and govalidator rules are applied to each element of slice
+func (v *validator) checkRule(field Field, spec string, message string) bool {
+	name, param := spec, ""
+	if match := paramRuleRegex.FindStringSubmatch(spec); match != nil {
+		name, param = match[1], match[2]
+	}
+	field.Param = param
+	value := indirect(field.Value)
+
+	if isGovalidatorRule(name) && value.IsValid() && isElementsContainer(value) {
+		valid := true
+		for i := 0; i < value.Len(); i++ {
+			elem := field
+			elem.Value = value.Index(i)
+			elem.Path = fmt.Sprintf("%s[%d]", field.Path, i)
+			valid = v.checkRule(elem, spec, message) && valid
+		}
+		return valid
+	}
+
+	err := applyRule(field, value, spec, name)
+	var unknown unknownRuleError
+	if errors.As(err, &unknown) {
+		v.err = errors.Errorf("invalid validation tag of %s: %v", field.Path, err)
+		return false
+	}
+	if err != nil {
+		if message == "" {
+			message = err.Error()
+		}
+		v.add(field.Path, message)
+		return false
+	}
+	return true
+}
+
+// applyRule checks field, value is dereferenced field value, it is invalid for nil pointer
+func applyRule(field Field, value reflect.Value, spec string, name string) error {
+	empty := !value.IsValid() || isEmpty(value)
+	switch name {
+	case "required":
+		if empty {
+			return errors.New("non zero value required")
+		}
+		return nil
+	case "optional":
+		return nil
+	}
+
+	if rule, ok := getRule(name); ok {
+		if empty && !strings.HasPrefix(name, "required") {
+			return nil
+		}
+		return rule(field)
+	}
+	if empty {
+		return nil
+	}
+	return govalidatorRule(field, value, spec, name)
+}
+
+func govalidatorRule(field Field, value reflect.Value, spec string, name string) error {
+	negate := strings.HasPrefix(name, "!")
+	name = strings.TrimPrefix(name, "!")
+	iface := value.Interface()
+
+	if custom, ok := govalidator.CustomTypeTagMap.Get(name); ok {
+		if custom(iface, field.Parent.Interface()) == negate {
+			return notValid(iface, spec)
+		}
+		return nil
+	}
+
+	_, isTag := govalidator.TagMap[name]
+	_, isParamTag := govalidator.ParamTagMap[name]
+	if !isTag && !isParamTag {
+		return unknownRuleError{name: name}
+	}
+	if !isScalar(value) {
+		return errors.Errorf("validator %s can't be applied to %s", name, value.Kind())
+	}
+	str := govalidator.ToString(iface)
+	if validate, ok := govalidator.TagMap[name]; ok {
+		if validate(str) == negate {
+			return notValid(iface, spec)
+		}
+		return nil
+	}
+	validate := govalidator.ParamTagMap[name]
+	params := []string{field.Param}
+	if regex, ok := govalidator.ParamTagRegexMap[name]; ok {
+		if match := regex.FindStringSubmatch(strings.TrimPrefix(spec, "!")); len(match) > 1 {
+			params = match[1:]
+		}
+	}
+	if validate(str, params...) == negate {
+		return notValid(iface, spec)
+	}
+	return nil
+}
+
+type unknownRuleError struct {
+	name string
+}
+
+func (e unknownRuleError) Error() string {
+	return "unknown validator " + e.name
+}
+
+// isGovalidatorRule returns true for rules which are applied to each element of slice
+func isGovalidatorRule(name string) bool {
+	if name == "required" || name == "optional" {
+		return false
+	}
+	if _, ok := getRule(name); ok {
+		return false
+	}
+	_, custom := govalidator.CustomTypeTagMap.Get(strings.TrimPrefix(name, "!"))
+	return !custom
+}
+
+func isElementsContainer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func notValid(value interface{}, spec string) error {
+	return errors.Errorf("%v does not validate as %s", value, spec)
+}
+
+// jsonName returns name of field in json, false if field is skipped in json
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return utils.ToCamelCase(f.Name), true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	if name == "" {
+		return path
+	}
+	return path + "." + name
+}
+
+func isScalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name  string `valid:"required~Required"`
+	Count int    `json:"count_value" valid:"range(1|10)"`
+}
+
+type testAddress struct {
+	City string `valid:"required~Required"`
+}
+
+type testRequest struct {
+	Id              string `valid:"required~Required,uuid~Invalid uuid"`
+	Email           string `valid:"email"`
+	Items           []testItem
+	Address         *testAddress
+	Labels          map[string]testAddress
+	Password        string
+	PasswordConfirm string `valid:"eqfield(Password)~Passwords mismatch"`
+	Phone           string `valid:"testphone"`
+	StartDate       string
+	EndDate         string `valid:"requiredwith(StartDate)"`
+	ignored         string `valid:"required"`
+}
+
+func (r testRequest) Validate() error {
+	if r.StartDate != "" && r.EndDate != "" && r.StartDate > r.EndDate {
+		return Errors{{Field: "endDate", Message: "must be after start date"}}
+	}
+	return nil
+}
+
+type testPositive struct {
+	Value int
+}
+
+func (p *testPositive) Validate() error {
+	if p.Value <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	RegisterRule("testphone", func(field Field) error {
+		if !strings.HasPrefix(field.Value.String(), "+") {
+			return errors.New("phone must start with +")
+		}
+		return nil
+	})
+
+	valid := testRequest{
+		Id:    "e5f0b8f0-3ffc-4c8b-9e3a-2f7b5b0c1a7d",
+		Items: []testItem{{Name: "a", Count: 1}},
+	}
+	assert.NoError(Validate(valid))
+	assert.NoError(Validate(&valid))
+
+	err := Validate(&testRequest{
+		Email:           "not email",
+		Items:           []testItem{{Name: "a", Count: 1}, {Count: 20}, {}},
+		Address:         &testAddress{},
+		Labels:          map[string]testAddress{"home": {}},
+		Password:        "secret",
+		PasswordConfirm: "other",
+		Phone:           "123",
+		StartDate:       "2022-01-02",
+	})
+	var errs Errors
+	assert.True(errors.As(err, &errs))
+	assert.Equal(map[string]string{
+		"id":                   "Required",
+		"email":                "not email does not validate as email",
+		"items[1].name":        "Required",
+		"items[1].count_value": "20 does not validate as range(1|10)",
+		"items[2].name":        "Required",
+		"address.city":         "Required",
+		"labels.home.city":     "Required",
+		"passwordConfirm":      "Passwords mismatch",
+		"phone":                "phone must start with +",
+		"endDate":              "required with StartDate",
+	}, errs.ByField())
+
+	err = Validate(testRequest{
+		Id:        "e5f0b8f0-3ffc-4c8b-9e3a-2f7b5b0c1a7d",
+		StartDate: "2022-01-02",
+		EndDate:   "2022-01-01",
+	})
+	assert.Equal(Errors{{Field: "endDate", Message: "must be after start date"}}, err)
+}
+
+func TestValidate_StructLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	err := Validate([]testPositive{{Value: 1}, {Value: 0}})
+	assert.Equal(Errors{{Field: "[1]", Message: "must be positive"}}, err)
+	err = Validate(&testPositive{})
+	assert.Equal(Errors{{Field: "", Message: "must be positive"}}, err)
+	assert.Equal("must be positive", err.Error())
+	assert.NoError(Validate(nil))
+}
+
+func TestValidate_SlicesAndPointers(t *testing.T) {
+	assert := assert.New(t)
+	type request struct {
+		Ids  []string `valid:"uuid~invalid id"`
+		Name *string  `valid:"length(1|5)"`
+	}
+
+	name := "abc"
+	assert.NoError(Validate(request{Ids: []string{"e5f0b8f0-3ffc-4c8b-9e3a-2f7b5b0c1a7d"}, Name: &name}))
+	assert.NoError(Validate(request{}))
+
+	long := "abcdef"
+	err := Validate(request{Ids: []string{"e5f0b8f0-3ffc-4c8b-9e3a-2f7b5b0c1a7d", "1"}, Name: &long})
+	assert.Equal(Errors{
+		{Field: "ids[1]", Message: "invalid id"},
+		{Field: "name", Message: "abcdef does not validate as length(1|5)"},
+	}, err)
+}
+
+func TestValidate_UnknownRule(t *testing.T) {
+	type request struct {
+		Name string `valid:"notexists"`
+	}
+	err := Validate(request{Name: "a"})
+	var errs Errors
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &errs))
+}